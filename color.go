@@ -0,0 +1,33 @@
+package logger
+
+import "os"
+
+// ColorMode 屏幕输出的颜色模式, 日志文件始终不带颜色
+type ColorMode int
+
+const (
+	ColorAuto   ColorMode = iota // 标准输出是终端且未设置NO_COLOR时带颜色
+	ColorAlways                  // 总是带颜色
+	ColorNever                   // 从不带颜色
+)
+
+func (m ColorMode) enabled() bool {
+	switch m {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CompressFunc 将src压缩写入dst
+type CompressFunc func(dst io.Writer, src io.Reader) error
+
+// Compression 历史日志文件压缩方式, Ext为压缩后追加的文件后缀
+// 本包只内置gzip, 不引入第三方依赖; 如需zstd, 可自行基于第三方库构造: &Compression{Ext: ".zst", Compress: ...}
+type Compression struct {
+	Ext      string
+	Compress CompressFunc
+}
+
+var GzipCompression = &Compression{
+	Ext: ".gz",
+	Compress: func(dst io.Writer, src io.Reader) error {
+		gw := gzip.NewWriter(dst)
+		if _, err := io.Copy(gw, src); err != nil {
+			return err
+		}
+		return gw.Close()
+	},
+}
+
+const (
+	compressChanLen = 64
+	compressTmpExt  = ".tmp"
+)
+
+func (w *FileLoggerWriter) needCompress() bool {
+	return w.compression != nil
+}
+
+// triggerCompress 把已经切换掉的文件交给后台压缩, 不阻塞写日志协程
+func (w *FileLoggerWriter) triggerCompress(name string) {
+	if !w.needCompress() {
+		return
+	}
+	name = filepath.Clean(name)
+	w.compressing.Store(name, struct{}{})
+	select {
+	case w.compressCh <- name:
+	default:
+		// 队列满了就留给下次启动时处理
+		w.compressing.Delete(name)
+		w.reportError(fmt.Errorf("log compress queue full, skip %s", name))
+	}
+}
+
+// compressLeftovers 处理上次进程退出时没来得及压缩的文件
+func (w *FileLoggerWriter) compressLeftovers(current string) {
+	entries, err := os.ReadDir(w.baseDir)
+	if err != nil {
+		w.reportError(fmt.Errorf("scan log files to compress failed: %w", err))
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.Type().IsRegular() || !strings.HasSuffix(name, ".log") || !w.isBackupFile(name, current) {
+			continue
+		}
+		path := filepath.Join(w.baseDir, name)
+		if _, queued := w.compressing.LoadOrStore(path, struct{}{}); queued {
+			continue
+		}
+		select {
+		case w.compressCh <- path:
+		case <-w.doneCh:
+			w.compressing.Delete(path)
+			return
+		}
+	}
+}
+
+func (w *FileLoggerWriter) compressLoop() {
+	for {
+		select {
+		case name := <-w.compressCh:
+			err := w.compressFile(name)
+			w.compressing.Delete(name)
+			if err != nil {
+				w.reportError(fmt.Errorf("compress log file failed: %w", err))
+				continue
+			}
+			w.triggerPrune()
+		case <-w.doneCh:
+			return
+		}
+	}
+}
+
+func (w *FileLoggerWriter) compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	// 先写临时文件, 设置好属主和时间后再改名, 清理时不会看到写了一半的压缩文件
+	dstName := name + w.compression.Ext
+	tmpName := dstName + compressTmpExt
+	dst, err := os.OpenFile(tmpName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return err
+	}
+
+	if err = w.compression.Compress(dst, src); err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = chownAs(tmpName, info)
+	}
+	// 保留原文件时间, 以免影响按时间清理
+	if err == nil {
+		err = os.Chtimes(tmpName, info.ModTime(), info.ModTime())
+	}
+	if err == nil {
+		err = os.Rename(tmpName, dstName)
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Remove(name)
+}
@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func readGzip(t *testing.T, name string) string {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCompressWithMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local)
+	clock := newTestClock(start)
+	policy := NewRotationPolicy("app", DailyPeriod, time.Local, clock.Now)
+	w := NewFileLoggerWriter(dir, 16, 0, policy, 16, 0755,
+		WithWriterMaxBackups(2), WithWriterCompression(GzipCompression), WithWriterClock(clock.Now))
+	startWriter(t, w)
+
+	for i := 0; i < 5; i++ {
+		writeAndStamp(t, w, clock, fmt.Sprintf("line %02d 0123456789\n", i))
+		clock.Add(time.Second)
+	}
+
+	current := filepath.Join(dir, "app.2026-10-17.log")
+	newest := backUpName(current, start.Add(4*time.Second)) + ".gz"
+	older := backUpName(current, start.Add(3*time.Second)) + ".gz"
+	waitFiles(t, dir, filepath.Base(current), filepath.Base(newest), filepath.Base(older))
+
+	if got := readGzip(t, newest); got != "line 03 0123456789\n" {
+		t.Errorf("newest backup = %q", got)
+	}
+	if got := readGzip(t, older); got != "line 02 0123456789\n" {
+		t.Errorf("older backup = %q", got)
+	}
+}
+
+func TestCompressLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Date(2026, 10, 16, 23, 0, 0, 0, time.Local)
+	touch(t, filepath.Join(dir, "app.2026-10-16.log"), "yesterday\n", modTime)
+	touch(t, filepath.Join(dir, "app.2026-10-15.log.gz"), "", modTime)
+	touch(t, filepath.Join(dir, "other.2026-10-16.log"), "other\n", modTime)
+
+	clock := newTestClock(time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local))
+	policy := NewRotationPolicy("app", DailyPeriod, time.Local, clock.Now)
+	w := NewFileLoggerWriter(dir, 1<<20, 0, policy, 16, 0755,
+		WithWriterCompression(GzipCompression), WithWriterClock(clock.Now))
+	startWriter(t, w)
+	w.Write("today\n")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFiles(t, dir, "app.2026-10-15.log.gz", "app.2026-10-16.log.gz", "app.2026-10-17.log", "other.2026-10-16.log")
+	compressed := filepath.Join(dir, "app.2026-10-16.log.gz")
+	if got := readGzip(t, compressed); got != "yesterday\n" {
+		t.Errorf("compressed = %q", got)
+	}
+	info, err := os.Stat(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("compressed mod time = %v, want %v", info.ModTime(), modTime)
+	}
+}
+
+func TestPruneCompressedGroups(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	files := []struct {
+		name string
+		age  time.Duration
+	}{
+		{"app.2026-10-17.log", 0},
+		// 压缩到一半, 两个文件算作一个历史文件
+		{"app.2026-10-16.log", 24 * time.Hour},
+		{"app.2026-10-16.log.gz", 24 * time.Hour},
+		{"app.2026-10-15.log.gz", 48 * time.Hour},
+		{"app.2026-10-14.log.gz", 72 * time.Hour},
+		// 正在压缩的文件不参与清理
+		{"app.2026-10-13.log", 96 * time.Hour},
+	}
+	for _, f := range files {
+		touch(t, filepath.Join(dir, f.name), "x\n", now.Add(-f.age))
+	}
+
+	w := NewFileLoggerWriter(dir, 1<<20, 0, nil, 16, 0755, WithWriterMaxBackups(2),
+		WithWriterCompression(GzipCompression), WithWriterClock(func() time.Time { return now }))
+	startWriter(t, w)
+	w.compressing.Store(filepath.Join(dir, "app.2026-10-13.log"), struct{}{})
+	if err := w.prune("app.2026-10-17.log"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"app.2026-10-13.log", "app.2026-10-15.log.gz", "app.2026-10-16.log",
+		"app.2026-10-16.log.gz", "app.2026-10-17.log"}
+	if got := listDir(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
@@ -14,6 +14,7 @@ const (
 	LogFileMaxSize   = 1024 * 1024 * 500
 	fileMode         = 0777
 	backupTimeFormat = "2006-01-02T15-04-05.000"
+	errorFileSuffix  = ".error"
 )
 
 const (
@@ -25,3 +26,13 @@ const (
 	stackColor = "\033[31m[Stack] %s\033[0m"
 	fatalColor = "\033[31m[Fatal] %s\033[0m"
 )
+
+const (
+	traceTag = "[Trace] %s"
+	debugTag = "[Debug] %s"
+	infoTag  = "[Info] %s"
+	warnTag  = "[Warn] %s"
+	errorTag = "[Error] %s"
+	stackTag = "[Stack] %s"
+	fatalTag = "[Fatal] %s"
+)
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const levelCount = FatalLevel + 1
+
+// DropStats 因队列满被丢弃的日志统计, 按等级分别计数
+type DropStats struct {
+	Records [levelCount]uint64
+	Bytes   [levelCount]uint64
+}
+
+// TotalRecords 丢弃的日志总条数
+func (s DropStats) TotalRecords() uint64 {
+	var total uint64
+	for _, n := range s.Records {
+		total += n
+	}
+	return total
+}
+
+// TotalBytes 丢弃的日志总字节数
+func (s DropStats) TotalBytes() uint64 {
+	var total uint64
+	for _, n := range s.Bytes {
+		total += n
+	}
+	return total
+}
+
+func (s *DropStats) add(o DropStats) {
+	for lv := 0; lv < levelCount; lv++ {
+		s.Records[lv] += o.Records[lv]
+		s.Bytes[lv] += o.Bytes[lv]
+	}
+}
+
+type dropCounter struct {
+	records [levelCount]atomic.Uint64
+	bytes   [levelCount]atomic.Uint64
+
+	// 上次报告之后的丢弃情况
+	pendingRecords atomic.Uint64
+	pendingBytes   atomic.Uint64
+	pendingSince   atomic.Int64
+}
+
+func clampLevel(level int) int {
+	if level < TraceLevel {
+		return TraceLevel
+	}
+	if level > FatalLevel {
+		return FatalLevel
+	}
+	return level
+}
+
+func (c *dropCounter) add(level int, size int) {
+	level = clampLevel(level)
+	c.records[level].Add(1)
+	c.bytes[level].Add(uint64(size))
+
+	c.pendingSince.CompareAndSwap(0, time.Now().UnixNano())
+	c.pendingBytes.Add(uint64(size))
+	c.pendingRecords.Add(1)
+}
+
+func (c *dropCounter) stats() DropStats {
+	var s DropStats
+	for lv := 0; lv < levelCount; lv++ {
+		s.Records[lv] = c.records[lv].Load()
+		s.Bytes[lv] = c.bytes[lv].Load()
+	}
+	return s
+}
+
+// takeReport 取出上次报告之后的丢弃情况, 没有丢弃时返回空
+func (c *dropCounter) takeReport(loc *time.Location) string {
+	if c.pendingRecords.Load() == 0 {
+		return ""
+	}
+	since := c.pendingSince.Swap(0)
+	records := c.pendingRecords.Swap(0)
+	bytes := c.pendingBytes.Swap(0)
+	if records == 0 {
+		return ""
+	}
+	return fmt.Sprintf("log queue overflow, dropped %d records (%d bytes) since %s",
+		records, bytes, time.Unix(0, since).In(loc).Format(TimeFormatDefault))
+}
+
+func defaultNoticeFormatter(level int, msg string) string {
+	return fmt.Sprintf("%s [%s] %s\n", buildTimeInfo(time.Now()), levelNames[level], msg)
+}
+
+// Dropped 返回因队列满被丢弃的日志统计
+func (w *FileLoggerWriter) Dropped() DropStats {
+	return w.dropped.stats()
+}
+
+// reportDropped 队列恢复后把丢弃情况写入日志文件
+func (w *FileLoggerWriter) reportDropped() {
+	if len(w.bufCh) > 0 {
+		return
+	}
+	if report := w.dropped.takeReport(w.location); report != "" {
+		_ = w.writeBuf([]byte(w.noticeFormatter(WarnLevel, report)))
+	}
+}
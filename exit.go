@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultFatalFlushTimeout Fatal日志退出前等待写完日志的默认时长
+const DefaultFatalFlushTimeout = 3 * time.Second
+
+var (
+	osExit      = os.Exit
+	exitHooksMu sync.Mutex
+	exitHooks   []func()
+)
+
+// RegisterExitHook 注册Fatal日志退出进程前执行的函数, 按注册顺序执行
+func RegisterExitHook(hook func()) {
+	if hook == nil {
+		return
+	}
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+	exitHooks = append(exitHooks, hook)
+}
+
+func runExitHooks() {
+	exitHooksMu.Lock()
+	hooks := make([]func(), len(exitHooks))
+	copy(hooks, exitHooks)
+	exitHooksMu.Unlock()
+
+	for _, hook := range hooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					fmt.Fprintln(os.Stderr, "log exit hook panic:", r)
+				}
+			}()
+			hook()
+		}()
+	}
+}
+
+// exit 执行退出钩子, 再写完并同步所有缓冲中的日志后退出进程
+// 钩子和写日志各自最多等待fatalFlushTimeout, 钩子超时不影响Fatal日志落盘
+func (l *Logger) exit() {
+	timeout := l.fatalFlushTimeout
+	if timeout <= 0 {
+		timeout = DefaultFatalFlushTimeout
+	}
+
+	hooksDone := make(chan struct{})
+	go func() {
+		runExitHooks()
+		close(hooksDone)
+	}()
+	hookTimer := time.NewTimer(timeout)
+	select {
+	case <-hooksDone:
+	case <-hookTimer.C:
+	}
+	hookTimer.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := l.root().Close(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "flush log before exit failed:", err)
+	}
+	osExit(1)
+}
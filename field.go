@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Field 结构化日志字段
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err 以error为key记录错误
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// ValueString 字段值的文本形式
+func (f Field) ValueString() string {
+	switch v := f.Value.(type) {
+	case string:
+		return v
+	case error:
+		return callString(v, v.Error)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return callString(v, v.String)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// callString 调用Error或String方法, 值为nil指针时返回<nil>, 其他panic记为<PANIC=...>, 不让写日志的协程崩溃
+func callString(value interface{}, fn func() string) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr && rv.IsNil() {
+				s = "<nil>"
+				return
+			}
+			s = fmt.Sprintf("<PANIC=%v>", r)
+		}
+	}()
+	return fn()
+}
+
+func buildFields(fields []Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	var builder strings.Builder
+	for _, f := range fields {
+		builder.WriteString(" ")
+		builder.WriteString(f.Key)
+		builder.WriteString("=")
+		builder.WriteString(f.ValueString())
+	}
+	return builder.String()
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type nilPtrError struct{ msg string }
+
+func (e *nilPtrError) Error() string { return e.msg }
+
+// nilSafeError 方法自己处理了nil接收者, 不能被当成panic处理
+type nilSafeError struct{}
+
+func (e *nilSafeError) Error() string {
+	if e == nil {
+		return "nil safe"
+	}
+	return "not nil"
+}
+
+type panicStringer struct{}
+
+func (panicStringer) String() string { panic("boom") }
+
+func TestFieldValueString(t *testing.T) {
+	tests := []struct {
+		name  string
+		field Field
+		want  string
+	}{
+		{"string", String("k", "v"), "v"},
+		{"error", Err(errors.New("failed")), "failed"},
+		{"nil error", Err(nil), "<nil>"},
+		{"typed nil error", Err((*nilPtrError)(nil)), "<nil>"},
+		{"nil safe error", Err((*nilSafeError)(nil)), "nil safe"},
+		{"panic stringer", Any("k", panicStringer{}), "<PANIC=boom>"},
+		{"time", Time("k", time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)), "2026-10-17T08:00:00Z"},
+		{"any", Any("k", 7), "7"},
+	}
+	for _, tt := range tests {
+		if got := tt.field.ValueString(); got != tt.want {
+			t.Errorf("%s: ValueString() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Format 日志输出格式
+type Format int
+
+const (
+	TextFormat   Format = iota // 默认文本格式
+	JSONFormat                 // 每行一个JSON对象
+	LogfmtFormat               // logfmt, key=value形式
+)
+
+// 时间格式, 除下列取值外也可以使用任意time包的layout
+const (
+	TimeFormatDefault     = "01-02 15:04:05.0000"                 // 文本格式默认, 不带年份
+	TimeFormatDateTime    = "2006-01-02 15:04:05.000000"          // 带年份, 微秒
+	TimeFormatISO8601     = "2006-01-02T15:04:05.000Z07:00"       // JSON/logfmt默认, 毫秒
+	TimeFormatRFC3339Nano = "2006-01-02T15:04:05.000000000Z07:00" // 纳秒, 固定宽度
+	TimeFormatEpochMillis = "epoch_millis"                        // 毫秒时间戳
+)
+
+// formatTime 按layout格式化时间, 小数秒使用0占位以保证宽度固定
+func formatTime(t time.Time, layout string) string {
+	if layout == TimeFormatEpochMillis {
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	}
+	return t.Format(fixedWidthLayout(layout))
+}
+
+// fixedWidthLayout 把小数秒的.999换成.000, 末尾的0不会被省略
+func fixedWidthLayout(layout string) string {
+	if !strings.Contains(layout, ".9") && !strings.Contains(layout, ",9") {
+		return layout
+	}
+	b := []byte(layout)
+	for i := 0; i+1 < len(b); i++ {
+		if (b[i] != '.' && b[i] != ',') || b[i+1] != '9' {
+			continue
+		}
+		j := i + 1
+		for j < len(b) && b[j] == '9' {
+			j++
+		}
+		// 与time包相同, 后面紧跟其他数字时不是小数秒
+		if j < len(b) && b[j] >= '0' && b[j] <= '9' {
+			continue
+		}
+		for k := i + 1; k < j; k++ {
+			b[k] = '0'
+		}
+		i = j - 1
+	}
+	return string(b)
+}
+
+var levelNames = [...]string{
+	TraceLevel: "trace",
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+	StackLevel: "stack",
+	FatalLevel: "fatal",
+}
+
+// Record 一条日志的全部信息, 由Encoder编码
+type Record struct {
+	Time    time.Time
+	Level   int
+	App     string
+	Prefix  string
+	Module  string
+	Caller  *CallInfoSt
+	Message string
+	Fields  []Field
+	Stack   string
+}
+
+// Encoder 把一条日志编码写入buf, 需要自行追加换行
+type Encoder interface {
+	Encode(buf *bytes.Buffer, r *Record) error
+}
+
+// TextEncoder 默认文本格式, Color为true时带颜色, TimeFormat为空时使用TimeFormatDefault
+type TextEncoder struct {
+	Color      bool
+	TimeFormat string
+}
+
+func (e TextEncoder) Encode(buf *bytes.Buffer, r *Record) error {
+	tag := levelTags[r.Level]
+	if e.Color {
+		tag = levelColors[r.Level]
+	}
+	timeInfo := buildTimeInfo(r.Time)
+	if e.TimeFormat != "" {
+		timeInfo = formatTime(r.Time, e.TimeFormat)
+	}
+	prefix := r.Prefix
+	if r.Module != "" {
+		prefix += "[" + r.Module + "]"
+	}
+	buf.WriteString(buildRecord(r.Level, tag, timeInfo, buildCallInfo(r.Caller), prefix, r.Message, r.Fields, r.Stack))
+	return nil
+}
+
+// JSONEncoder 每行一个JSON对象, TimeFormat为空时使用TimeFormatISO8601
+type JSONEncoder struct {
+	TimeFormat string
+}
+
+func (e JSONEncoder) Encode(buf *bytes.Buffer, r *Record) error {
+	buf.WriteString(encodeJSON(r, e.TimeFormat))
+	return nil
+}
+
+// LogfmtEncoder logfmt, key=value形式, TimeFormat为空时使用TimeFormatISO8601
+type LogfmtEncoder struct {
+	TimeFormat string
+}
+
+func (e LogfmtEncoder) Encode(buf *bytes.Buffer, r *Record) error {
+	buf.WriteString(encodeLogfmt(r, e.TimeFormat))
+	return nil
+}
+
+// encoder color只对文本格式有效, 写文件时不带颜色, timeFormat为空时使用各格式的默认值
+func (f Format) encoder(color bool, timeFormat string) Encoder {
+	switch f {
+	case JSONFormat:
+		return JSONEncoder{TimeFormat: timeFormat}
+	case LogfmtFormat:
+		return LogfmtEncoder{TimeFormat: timeFormat}
+	default:
+		return TextEncoder{Color: color, TimeFormat: timeFormat}
+	}
+}
+
+// encodeRecord 编码失败时退回默认文本格式
+func encodeRecord(enc Encoder, r *Record) string {
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, r); err != nil {
+		buf.Reset()
+		TextEncoder{}.Encode(&buf, r)
+	}
+	return buf.String()
+}
+
+func writeJSONString(builder *strings.Builder, s string) {
+	b, _ := json.Marshal(s)
+	builder.Write(b)
+}
+
+func writeJSONKey(builder *strings.Builder, key string) {
+	writeJSONString(builder, key)
+	builder.WriteString(":")
+}
+
+func writeJSONValue(builder *strings.Builder, f Field) {
+	switch v := f.Value.(type) {
+	case nil:
+		builder.WriteString("null")
+		return
+	case string, error, time.Time, time.Duration:
+		writeJSONString(builder, f.ValueString())
+		return
+	case int:
+		builder.WriteString(strconv.Itoa(v))
+		return
+	case int64:
+		builder.WriteString(strconv.FormatInt(v, 10))
+		return
+	case uint64:
+		builder.WriteString(strconv.FormatUint(v, 10))
+		return
+	case bool:
+		builder.WriteString(strconv.FormatBool(v))
+		return
+	}
+
+	b, err := json.Marshal(f.Value)
+	if err != nil {
+		writeJSONString(builder, f.ValueString())
+		return
+	}
+	builder.Write(b)
+}
+
+func encodeJSON(r *Record, timeFormat string) string {
+	if timeFormat == "" {
+		timeFormat = TimeFormatISO8601
+	}
+	var builder strings.Builder
+	builder.WriteString("{")
+	writeJSONKey(&builder, "time")
+	if timeFormat == TimeFormatEpochMillis {
+		builder.WriteString(formatTime(r.Time, timeFormat))
+	} else {
+		writeJSONString(&builder, formatTime(r.Time, timeFormat))
+	}
+	builder.WriteString(",")
+	writeJSONKey(&builder, "level")
+	writeJSONString(&builder, levelNames[r.Level])
+	if r.App != "" {
+		builder.WriteString(",")
+		writeJSONKey(&builder, "app")
+		writeJSONString(&builder, r.App)
+	}
+	if r.Prefix != "" {
+		builder.WriteString(",")
+		writeJSONKey(&builder, "prefix")
+		writeJSONString(&builder, r.Prefix)
+	}
+	if r.Module != "" {
+		builder.WriteString(",")
+		writeJSONKey(&builder, "module")
+		writeJSONString(&builder, r.Module)
+	}
+	if r.Caller != nil {
+		builder.WriteString(",")
+		writeJSONKey(&builder, "file")
+		writeJSONString(&builder, r.Caller.File)
+		builder.WriteString(",")
+		writeJSONKey(&builder, "line")
+		builder.WriteString(strconv.Itoa(r.Caller.Line))
+		builder.WriteString(",")
+		writeJSONKey(&builder, "func")
+		writeJSONString(&builder, r.Caller.FuncName)
+	}
+	builder.WriteString(",")
+	writeJSONKey(&builder, "msg")
+	writeJSONString(&builder, r.Message)
+	if len(r.Fields) > 0 {
+		builder.WriteString(",")
+		writeJSONKey(&builder, "fields")
+		builder.WriteString("{")
+		for i, f := range r.Fields {
+			if i > 0 {
+				builder.WriteString(",")
+			}
+			writeJSONKey(&builder, f.Key)
+			writeJSONValue(&builder, f)
+		}
+		builder.WriteString("}")
+	}
+	if r.Stack != "" {
+		builder.WriteString(",")
+		writeJSONKey(&builder, "stack")
+		writeJSONString(&builder, r.Stack)
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// logfmtNeedQuote 值为空或包含空白、等号、引号、控制字符时需要加引号
+func logfmtNeedQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, c := range s {
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f || !unicode.IsPrint(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeLogfmtKey(builder *strings.Builder, key string) {
+	if builder.Len() > 0 {
+		builder.WriteString(" ")
+	}
+	if key == "" {
+		key = "_"
+	}
+	for _, c := range key {
+		if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			builder.WriteRune('_')
+			continue
+		}
+		builder.WriteRune(c)
+	}
+	builder.WriteString("=")
+}
+
+func writeLogfmtValue(builder *strings.Builder, value string) {
+	if logfmtNeedQuote(value) {
+		builder.WriteString(strconv.Quote(value))
+		return
+	}
+	builder.WriteString(value)
+}
+
+func encodeLogfmt(r *Record, timeFormat string) string {
+	if timeFormat == "" {
+		timeFormat = TimeFormatISO8601
+	}
+	var builder strings.Builder
+	writeLogfmtKey(&builder, "time")
+	writeLogfmtValue(&builder, formatTime(r.Time, timeFormat))
+	writeLogfmtKey(&builder, "level")
+	writeLogfmtValue(&builder, levelNames[r.Level])
+	if r.App != "" {
+		writeLogfmtKey(&builder, "app")
+		writeLogfmtValue(&builder, r.App)
+	}
+	if r.Prefix != "" {
+		writeLogfmtKey(&builder, "prefix")
+		writeLogfmtValue(&builder, r.Prefix)
+	}
+	if r.Module != "" {
+		writeLogfmtKey(&builder, "module")
+		writeLogfmtValue(&builder, r.Module)
+	}
+	if r.Caller != nil {
+		writeLogfmtKey(&builder, "caller")
+		writeLogfmtValue(&builder, r.Caller.File+":"+strconv.Itoa(r.Caller.Line))
+		if r.Caller.FuncName != "" {
+			writeLogfmtKey(&builder, "func")
+			writeLogfmtValue(&builder, r.Caller.FuncName)
+		}
+	}
+	writeLogfmtKey(&builder, "msg")
+	writeLogfmtValue(&builder, r.Message)
+	for _, f := range r.Fields {
+		writeLogfmtKey(&builder, f.Key)
+		writeLogfmtValue(&builder, f.ValueString())
+	}
+	if r.Stack != "" {
+		writeLogfmtKey(&builder, "stack")
+		writeLogfmtValue(&builder, r.Stack)
+	}
+	builder.WriteString("\n")
+	return builder.String()
+}
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtValueQuoting(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"plain", "hello", "k=hello"},
+		{"empty", "", `k=""`},
+		{"space", "hello world", `k="hello world"`},
+		{"equals", "a=b", `k="a=b"`},
+		{"quote", `say "hi"`, `k="say \"hi\""`},
+		{"backslash", `C:\tmp`, `k="C:\\tmp"`},
+		{"newline", "a\nb", `k="a\nb"`},
+		{"tab", "a\tb", `k="a\tb"`},
+		{"control", "a\x01b", `k="a\x01b"`},
+		{"unicode", "你好", "k=你好"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var builder strings.Builder
+			writeLogfmtKey(&builder, "k")
+			writeLogfmtValue(&builder, tt.value)
+			if got := builder.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogfmtKeySanitize(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"user_id", "user_id="},
+		{"", "_="},
+		{"a b", "a_b="},
+		{"a=b", "a_b="},
+		{`a"b`, "a_b="},
+	}
+	for _, tt := range tests {
+		var builder strings.Builder
+		writeLogfmtKey(&builder, tt.key)
+		if got := builder.String(); got != tt.want {
+			t.Errorf("key %q: got %s, want %s", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	r := &Record{
+		Time:    time.Date(2026, 10, 17, 8, 30, 0, 0, time.UTC),
+		Level:   WarnLevel,
+		App:     "game",
+		Module:  "battle",
+		Caller:  &CallInfoSt{File: "battle/fight.go", Line: 12, FuncName: "Attack"},
+		Message: "hp low",
+		Fields:  []Field{Int("hp", 3), String("name", "big boss")},
+	}
+	var buf bytes.Buffer
+	if err := (LogfmtEncoder{}).Encode(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	want := `time=2026-10-17T08:30:00.000Z level=warn app=game module=battle caller=battle/fight.go:12 func=Attack msg="hp low" hp=3 name="big boss"` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// levelHandler 运行时查看和修改日志等级的http接口
+//
+//	GET  返回全局和各模块的等级, Accept为application/json或?format=json时返回JSON
+//	PUT  ?module=battle&ttl=5m 请求体为等级名, 如debug;
+//	     Content-Type为application/json时请求体为{"level":"debug","module":"battle","ttl":"5m"}
+//	     module为空时修改全局等级, ttl大于0时到期自动恢复原等级
+type levelHandler struct {
+	l       *Logger
+	mu      sync.Mutex
+	reverts map[string]*levelRevert // 模块名 -> 待执行的自动恢复, 全局等级的key为空串
+}
+
+// levelRevert 到期后恢复为prev
+type levelRevert struct {
+	timer *time.Timer
+	prev  int32
+}
+
+type levelRequest struct {
+	Level  string `json:"level"`
+	Module string `json:"module,omitempty"`
+	TTL    string `json:"ttl,omitempty"`
+}
+
+type levelResponse struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules,omitempty"`
+}
+
+// LevelHandler 返回修改logger等级的http.Handler, 建议只在本地或内网端口上暴露
+func (l *Logger) LevelHandler() http.Handler {
+	return &levelHandler{l: l, reverts: make(map[string]*levelRevert)}
+}
+
+// LevelHandler 返回修改默认logger等级的http.Handler
+func LevelHandler() http.Handler {
+	return Default().LevelHandler()
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		if err := h.update(r); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	h.write(w, r)
+}
+
+func wantJSON(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "json" ||
+		strings.Contains(r.Header.Get("Accept"), "application/json") ||
+		strings.Contains(r.Header.Get("Content-Type"), "application/json")
+}
+
+func (h *levelHandler) write(w http.ResponseWriter, r *http.Request) {
+	resp := levelResponse{Level: levelNames[h.l.GetLevel()], Modules: make(map[string]string)}
+	for module, lv := range h.l.ModuleLevels() {
+		resp.Modules[module] = levelNames[lv]
+	}
+
+	if wantJSON(r) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "level=%s\n", resp.Level)
+	for _, module := range h.l.ModuleNames() {
+		fmt.Fprintf(w, "%s=%s\n", module, resp.Modules[module])
+	}
+}
+
+func (h *levelHandler) parseRequest(r *http.Request) (*levelRequest, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 4096))
+	if err != nil {
+		return nil, err
+	}
+
+	req := &levelRequest{
+		Module: r.URL.Query().Get("module"),
+		TTL:    r.URL.Query().Get("ttl"),
+	}
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(body, req); err != nil {
+			return nil, err
+		}
+	} else {
+		req.Level = strings.TrimSpace(string(body))
+	}
+	if req.Level == "" {
+		req.Level = r.URL.Query().Get("level")
+	}
+	return req, nil
+}
+
+func (h *levelHandler) update(r *http.Request) error {
+	req, err := h.parseRequest(r)
+	if err != nil {
+		return err
+	}
+	lv, err := parseLevel(req.Level)
+	if err != nil {
+		return err
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil {
+			return err
+		}
+	}
+
+	target := h.l
+	if req.Module != "" {
+		child, ok := h.l.Module(req.Module)
+		if !ok {
+			return fmt.Errorf("unknown module %q", req.Module)
+		}
+		target = child
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// 还有未到期的自动恢复时, 恢复到的等级仍以最初的为准
+	prev := atomic.LoadInt32(&target.level)
+	if pending, ok := h.reverts[req.Module]; ok {
+		pending.timer.Stop()
+		prev = pending.prev
+		delete(h.reverts, req.Module)
+	}
+	target.SetLevel(lv)
+	if ttl > 0 {
+		module := req.Module
+		pending := &levelRevert{prev: prev}
+		pending.timer = time.AfterFunc(ttl, func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			// 已被后来的修改替换的定时器不再生效
+			if h.reverts[module] != pending {
+				return
+			}
+			atomic.StoreInt32(&target.level, pending.prev)
+			delete(h.reverts, module)
+		})
+		h.reverts[module] = pending
+	}
+	return nil
+}
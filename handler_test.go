@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newHandlerTestLogger(t *testing.T) *Logger {
+	l, err := New(WithPath(t.TempDir()), WithAppName("handler"), WithLevel(InfoLevel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close(context.Background()) })
+	l.Named("battle")
+	return l
+}
+
+func serveLevel(h http.Handler, method, target, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLevelHandlerGet(t *testing.T) {
+	l := newHandlerTestLogger(t)
+	l.SetModuleLevel("battle", DebugLevel)
+	h := l.LevelHandler()
+
+	rec := serveLevel(h, http.MethodGet, "/", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if got, want := rec.Body.String(), "level=info\nbattle=debug\n"; got != want {
+		t.Errorf("text body = %q, want %q", got, want)
+	}
+
+	rec = serveLevel(h, http.MethodGet, "/?format=json", "", "")
+	var resp levelResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Level != "info" || resp.Modules["battle"] != "debug" {
+		t.Errorf("json body = %+v", resp)
+	}
+}
+
+func TestLevelHandlerPut(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		wantCode    int
+		wantLevel   int
+		wantModule  int
+	}{
+		{"text global", "/", "", "debug", http.StatusOK, DebugLevel, DebugLevel},
+		{"query level", "/?level=warn", "", "", http.StatusOK, WarnLevel, WarnLevel},
+		{"text module", "/?module=battle", "", "trace", http.StatusOK, InfoLevel, TraceLevel},
+		{"json module", "/", "application/json", `{"level":"error","module":"battle"}`, http.StatusOK, InfoLevel, ErrorLevel},
+		{"bad level", "/", "", "bogus", http.StatusBadRequest, InfoLevel, InfoLevel},
+		{"bad json", "/", "application/json", `{"level":`, http.StatusBadRequest, InfoLevel, InfoLevel},
+		{"bad ttl", "/?ttl=soon", "", "debug", http.StatusBadRequest, InfoLevel, InfoLevel},
+		{"unknown module", "/?module=nope", "", "debug", http.StatusBadRequest, InfoLevel, InfoLevel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newHandlerTestLogger(t)
+			rec := serveLevel(l.LevelHandler(), http.MethodPut, tt.target, tt.contentType, tt.body)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			battle, _ := l.Module("battle")
+			if l.GetLevel() != tt.wantLevel || battle.GetLevel() != tt.wantModule {
+				t.Errorf("levels = %d/%d, want %d/%d", l.GetLevel(), battle.GetLevel(), tt.wantLevel, tt.wantModule)
+			}
+		})
+	}
+}
+
+func TestLevelHandlerMethodNotAllowed(t *testing.T) {
+	l := newHandlerTestLogger(t)
+	rec := serveLevel(l.LevelHandler(), http.MethodDelete, "/", "", "")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func waitLevel(t *testing.T, l *Logger, want int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for l.GetLevel() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("level = %d, want %d", l.GetLevel(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLevelHandlerTTL(t *testing.T) {
+	l := newHandlerTestLogger(t)
+	h := l.LevelHandler()
+
+	serveLevel(h, http.MethodPut, "/?ttl=30ms", "", "debug")
+	if l.GetLevel() != DebugLevel {
+		t.Fatalf("level = %d, want debug", l.GetLevel())
+	}
+	waitLevel(t, l, InfoLevel)
+
+	// 模块恢复为跟随父logger
+	serveLevel(h, http.MethodPut, "/?module=battle&ttl=30ms", "", "trace")
+	battle, _ := l.Module("battle")
+	l.SetLevel(WarnLevel)
+	waitLevel(t, battle, WarnLevel)
+}
+
+func TestLevelHandlerOverlappingTTL(t *testing.T) {
+	l := newHandlerTestLogger(t)
+	h := l.LevelHandler()
+
+	serveLevel(h, http.MethodPut, "/?ttl=200ms", "", "debug")
+	serveLevel(h, http.MethodPut, "/?ttl=50ms", "", "trace")
+	if l.GetLevel() != TraceLevel {
+		t.Fatalf("level = %d, want trace", l.GetLevel())
+	}
+	// 恢复到第一次修改前的等级, 而不是中间的debug
+	waitLevel(t, l, InfoLevel)
+	time.Sleep(250 * time.Millisecond)
+	if l.GetLevel() != InfoLevel {
+		t.Errorf("level = %d after first ttl, want info", l.GetLevel())
+	}
+}
+
+func TestLevelHandlerPutWithoutTTLCancelsRevert(t *testing.T) {
+	l := newHandlerTestLogger(t)
+	h := l.LevelHandler()
+
+	serveLevel(h, http.MethodPut, "/?ttl=30ms", "", "debug")
+	serveLevel(h, http.MethodPut, "/", "", "warn")
+	time.Sleep(80 * time.Millisecond)
+	if l.GetLevel() != WarnLevel {
+		t.Errorf("level = %d, want warn", l.GetLevel())
+	}
+}
@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	minRetryBackoff = 100 * time.Millisecond
+	maxRetryBackoff = 30 * time.Second
+)
+
+// WriterHealth writer的健康状态
+type WriterHealth struct {
+	LastError           error     // 最近一次写文件错误
+	LastErrorAt         time.Time // 最近一次写文件错误的时间
+	Failures            uint64    // 写文件失败总次数
+	ConsecutiveFailures uint64    // 连续失败次数, 0表示当前写文件正常
+}
+
+// Healthy 最近一次写文件是否成功
+func (h WriterHealth) Healthy() bool {
+	return h.ConsecutiveFailures == 0
+}
+
+// merge 合并多个writer的状态, 失败次数相加, 连续失败取最大, 最近错误取时间最晚的
+func (h WriterHealth) merge(o WriterHealth) WriterHealth {
+	h.Failures += o.Failures
+	if o.ConsecutiveFailures > h.ConsecutiveFailures {
+		h.ConsecutiveFailures = o.ConsecutiveFailures
+	}
+	if o.LastError != nil && o.LastErrorAt.After(h.LastErrorAt) {
+		h.LastError, h.LastErrorAt = o.LastError, o.LastErrorAt
+	}
+	return h
+}
+
+func defaultErrorHandler(err error) {
+	fmt.Fprintln(os.Stderr, "log writer error:", err)
+}
+
+// Health 返回writer的健康状态, 可用于健康检查
+func (w *FileLoggerWriter) Health() WriterHealth {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return WriterHealth{
+		LastError:           w.lastErr,
+		LastErrorAt:         w.lastErrAt,
+		Failures:            w.failures,
+		ConsecutiveFailures: w.consecutiveFailures,
+	}
+}
+
+// reportError 把后台错误交给错误处理函数
+func (w *FileLoggerWriter) reportError(err error) {
+	if w.errorHandler != nil {
+		w.errorHandler(err)
+	}
+}
+
+// writeBuf 写文件失败时改写到stderr, 并在退避时间内不再尝试写文件
+func (w *FileLoggerWriter) writeBuf(buf []byte) error {
+	if len(buf) == 0 {
+		return nil
+	}
+
+	if !w.retryAt.IsZero() && time.Now().Before(w.retryAt) {
+		w.fallback(buf)
+		return w.Health().LastError
+	}
+
+	err := w.writeFile(buf)
+	if err == nil {
+		w.recoverFromError()
+		return nil
+	}
+
+	w.onWriteError(err)
+	w.fallback(buf)
+	return err
+}
+
+func (w *FileLoggerWriter) fallback(buf []byte) {
+	os.Stderr.Write(buf)
+}
+
+func (w *FileLoggerWriter) onWriteError(err error) {
+	// 关闭当前文件, 下次重试时重新打开
+	w.close()
+	w.openCurrentFileTime = nil
+
+	if w.retryBackoff == 0 {
+		w.retryBackoff = minRetryBackoff
+	} else if w.retryBackoff *= 2; w.retryBackoff > maxRetryBackoff {
+		w.retryBackoff = maxRetryBackoff
+	}
+	w.retryAt = time.Now().Add(w.retryBackoff)
+
+	w.mu.Lock()
+	w.lastErr = err
+	w.lastErrAt = time.Now()
+	w.failures++
+	w.consecutiveFailures++
+	w.mu.Unlock()
+
+	w.reportError(err)
+}
+
+func (w *FileLoggerWriter) recoverFromError() {
+	if w.retryAt.IsZero() {
+		return
+	}
+	w.retryBackoff = 0
+	w.retryAt = time.Time{}
+
+	w.mu.Lock()
+	w.consecutiveFailures = 0
+	w.mu.Unlock()
+}
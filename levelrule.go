@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LevelRulesEnv 按包设置日志等级的环境变量, 格式同SetLevelRules
+const LevelRulesEnv = "TLOGLEVELS"
+
+const noRuleLevel = -1
+
+type levelRule struct {
+	pkg       string
+	recursive bool // pkg/* 同时匹配子包
+	level     int
+}
+
+// levelRuleSet 一组按包设置的等级, 规则不变, 修改时整体替换, 缓存随之失效
+type levelRuleSet struct {
+	rules    []levelRule
+	minLevel int
+	maxLevel int
+	cache    sync.Map // pc -> int, 调用位置命中的等级, noRuleLevel表示没有命中
+}
+
+// levelRules 根logger及其子logger共享
+type levelRules struct {
+	current atomic.Value // *levelRuleSet
+}
+
+func parseLevel(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for lv, name := range levelNames {
+		if s == name {
+			return lv, nil
+		}
+	}
+	lv, err := strconv.Atoi(s)
+	if err != nil || lv < TraceLevel || lv > FatalLevel {
+		return 0, fmt.Errorf("invalid log level %q", s)
+	}
+	return lv, nil
+}
+
+// parseLevelRules 解析"github.com/a/battle/*=debug,github.com/a/db=warn", 空串表示清空规则
+func parseLevelRules(spec string) (*levelRuleSet, error) {
+	set := &levelRuleSet{minLevel: FatalLevel, maxLevel: TraceLevel}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		idx := strings.LastIndex(item, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid log level rule %q", item)
+		}
+		lv, err := parseLevel(item[idx+1:])
+		if err != nil {
+			return nil, err
+		}
+		rule := levelRule{pkg: strings.TrimSpace(item[:idx]), level: lv}
+		if strings.HasSuffix(rule.pkg, "/*") {
+			rule.pkg = strings.TrimSuffix(rule.pkg, "/*")
+			rule.recursive = true
+		}
+		set.rules = append(set.rules, rule)
+		if lv < set.minLevel {
+			set.minLevel = lv
+		}
+		if lv > set.maxLevel {
+			set.maxLevel = lv
+		}
+	}
+	if len(set.rules) == 0 {
+		return nil, nil
+	}
+	return set, nil
+}
+
+// match 最长匹配的规则生效
+func (s *levelRuleSet) match(pkg string) int {
+	level, matched := noRuleLevel, -1
+	for _, rule := range s.rules {
+		ok := pkg == rule.pkg || (rule.recursive && strings.HasPrefix(pkg, rule.pkg+"/"))
+		if ok && len(rule.pkg) > matched {
+			level, matched = rule.level, len(rule.pkg)
+		}
+	}
+	return level
+}
+
+func (s *levelRuleSet) levelAt(pc uintptr) int {
+	if v, ok := s.cache.Load(pc); ok {
+		return v.(int)
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	pkg, _ := getPackageName(frame.Function)
+	level := s.match(pkg)
+	s.cache.Store(pc, level)
+	return level
+}
+
+func (r *levelRules) load() *levelRuleSet {
+	set, _ := r.current.Load().(*levelRuleSet)
+	return set
+}
+
+func (r *levelRules) set(spec string) error {
+	set, err := parseLevelRules(spec)
+	if err != nil {
+		return err
+	}
+	r.current.Store(set)
+	return nil
+}
+
+func newLevelRules() *levelRules {
+	r := &levelRules{}
+	r.current.Store((*levelRuleSet)(nil))
+	return r
+}
+
+// enabledAt 判断等级是否输出, skip与GetCallInfo相同, 需要在logf等函数中直接调用
+// 没有规则或等级在所有规则和logger等级之外时不需要取调用位置
+func (l *Logger) enabledAt(lv int, skip int) bool {
+	base := l.GetLevel()
+	set := l.rules.load()
+	if set == nil {
+		return lv >= base
+	}
+	if lv >= base && lv >= set.maxLevel {
+		return true
+	}
+	if lv < base && lv < set.minLevel {
+		return false
+	}
+
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return lv >= base
+	}
+	if level := set.levelAt(pcs[0]); level != noRuleLevel {
+		return lv >= level
+	}
+	return lv >= base
+}
+
+// SetLevelRules 按包设置日志等级, 如"github.com/a/battle/*=debug,github.com/a/db=warn"
+// pkg/*同时匹配子包, 多条规则命中时最长的生效, 空串清空规则
+func (l *Logger) SetLevelRules(spec string) error {
+	return l.rules.set(spec)
+}
+
+// SetLevelRules 设置默认logger的按包日志等级
+func SetLevelRules(spec string) error {
+	return Default().SetLevelRules(spec)
+}
+
+func levelRulesFromEnv() string {
+	return os.Getenv(LevelRulesEnv)
+}
@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"debug", DebugLevel, false},
+		{" WARN ", WarnLevel, false},
+		{"fatal", FatalLevel, false},
+		{"2", InfoLevel, false},
+		{"-1", 0, true},
+		{"99", 0, true},
+		{"bogus", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseLevel(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLevel(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseLevel(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseLevelRules(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr bool
+		rules   int
+		min     int
+		max     int
+	}{
+		{"", false, 0, 0, 0},
+		{" , ", false, 0, 0, 0},
+		{"a/b=debug", false, 1, DebugLevel, DebugLevel},
+		{"a/*=trace, a/b=error", false, 2, TraceLevel, ErrorLevel},
+		{"a/b", true, 0, 0, 0},
+		{"=debug", true, 0, 0, 0},
+		{"a/b=bogus", true, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		set, err := parseLevelRules(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseLevelRules(%q) err = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if tt.rules == 0 {
+			if set != nil {
+				t.Errorf("parseLevelRules(%q) = %+v, want nil", tt.spec, set)
+			}
+			continue
+		}
+		if len(set.rules) != tt.rules || set.minLevel != tt.min || set.maxLevel != tt.max {
+			t.Errorf("parseLevelRules(%q) = %d rules [%d,%d], want %d rules [%d,%d]",
+				tt.spec, len(set.rules), set.minLevel, set.maxLevel, tt.rules, tt.min, tt.max)
+		}
+	}
+}
+
+func TestLevelRuleMatch(t *testing.T) {
+	set, err := parseLevelRules("a/*=debug,a/b=error,a/b/c/*=trace,x=warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		pkg  string
+		want int
+	}{
+		{"a", DebugLevel},
+		{"a/z", DebugLevel},
+		{"a/b", ErrorLevel},
+		{"a/b/d", DebugLevel},
+		{"a/b/c", TraceLevel},
+		{"a/b/c/d", TraceLevel},
+		{"x", WarnLevel},
+		{"x/y", noRuleLevel},
+		{"ab", noRuleLevel},
+	}
+	for _, tt := range tests {
+		if got := set.match(tt.pkg); got != tt.want {
+			t.Errorf("match(%q) = %d, want %d", tt.pkg, got, tt.want)
+		}
+	}
+}
+
+func newRuleTestLogger(level int, spec string) *Logger {
+	l := &Logger{level: int32(level), rules: newLevelRules(), modules: newModuleRegistry()}
+	if err := l.SetLevelRules(spec); err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// enabledHere 在本包中调用, 调用位置的包名为github.com/hezhis/logger
+func enabledHere(l *Logger, lv int) bool {
+	return l.enabledAt(lv, 1)
+}
+
+func TestEnabledAt(t *testing.T) {
+	const pkg = "github.com/hezhis/logger"
+	tests := []struct {
+		name  string
+		level int
+		spec  string
+		lv    int
+		want  bool
+	}{
+		{"no rules above", InfoLevel, "", WarnLevel, true},
+		{"no rules below", InfoLevel, "", DebugLevel, false},
+		{"rule lowers", InfoLevel, pkg + "=debug", DebugLevel, true},
+		{"rule raises", InfoLevel, pkg + "=error", WarnLevel, false},
+		{"recursive rule", InfoLevel, "github.com/hezhis/*=trace", TraceLevel, true},
+		{"other package", InfoLevel, "github.com/other=trace", DebugLevel, false},
+		{"longest wins", InfoLevel, "github.com/hezhis/*=trace," + pkg + "=warn", InfoLevel, false},
+	}
+	for _, tt := range tests {
+		l := newRuleTestLogger(tt.level, tt.spec)
+		if got := enabledHere(l, tt.lv); got != tt.want {
+			t.Errorf("%s: enabledAt(%d) = %v, want %v", tt.name, tt.lv, got, tt.want)
+		}
+	}
+}
+
+func TestEnabledAtCache(t *testing.T) {
+	l := newRuleTestLogger(InfoLevel, "github.com/hezhis/logger=debug")
+	set := l.rules.load()
+
+	for i := 0; i < 3; i++ {
+		if !enabledHere(l, DebugLevel) {
+			t.Fatal("debug should be enabled by rule")
+		}
+	}
+	cached := 0
+	set.cache.Range(func(_, v interface{}) bool {
+		cached++
+		if v.(int) != DebugLevel {
+			t.Errorf("cached level = %d, want %d", v.(int), DebugLevel)
+		}
+		return true
+	})
+	if cached != 1 {
+		t.Errorf("cache entries = %d, want 1", cached)
+	}
+
+	// 替换规则后使用新的缓存
+	if err := l.SetLevelRules("github.com/hezhis/logger=error"); err != nil {
+		t.Fatal(err)
+	}
+	if enabledHere(l, WarnLevel) {
+		t.Error("warn should be disabled after rules change")
+	}
+	if err := l.SetLevelRules(""); err != nil {
+		t.Fatal(err)
+	}
+	if !enabledHere(l, WarnLevel) || enabledHere(l, DebugLevel) {
+		t.Error("logger level should apply after rules cleared")
+	}
+}
+
+func TestNewIgnoresInvalidEnvRules(t *testing.T) {
+	t.Setenv(LevelRulesEnv, "bogus")
+	var reported error
+	l, err := New(WithPath(t.TempDir()), WithAppName("rules"), WithErrorHandler(func(err error) { reported = err }))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer l.Close(context.Background())
+	if reported == nil {
+		t.Error("invalid rules should be reported")
+	}
+	if l.rules.load() != nil {
+		t.Error("invalid rules should be ignored")
+	}
+}
+
+func TestNewRejectsInvalidRules(t *testing.T) {
+	if _, err := New(WithPath(t.TempDir()), WithAppName("rules"), WithLevelRules("a/b=bogus")); err == nil {
+		t.Error("New with invalid WithLevelRules should fail")
+	}
+
+	l, err := New(WithPath(t.TempDir()), WithAppName("rules"), WithLevelRules("a/b=debug"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer l.Close(context.Background())
+	if set := l.rules.load(); set == nil || set.match("a/b") != DebugLevel {
+		t.Error("valid rules should be applied")
+	}
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path"
@@ -9,19 +10,49 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 )
 
-type logger struct {
-	name        string      // 日志名字
-	level       int         // 日志等级
-	bScreen     bool        // 是否打印屏幕
-	path        string      // 目录
-	prefix      string      // 标识
-	maxFileSize int64       // 文件大小
-	perm        os.FileMode // 文件权限
-	writer      *FileLoggerWriter
+// Logger 日志实例, 通过New创建, 各实例的名字、目录、等级和writer互相独立
+type Logger struct {
+	name              string                     // 日志名字
+	level             int32                      // 日志等级, 原子读写
+	configuredLevel   int                        // 初始化时配置的等级
+	bScreen           bool                       // 是否打印屏幕
+	path              string                     // 目录
+	prefix            string                     // 标识
+	maxFileSize       int64                      // 文件大小
+	perm              os.FileMode                // 文件权限
+	maxBackups        int                        // 保留历史文件个数
+	maxAge            time.Duration              // 历史文件保留时长
+	compression       *Compression               // 历史文件压缩方式
+	rotationPeriod    *RotationPeriod            // 文件切换周期
+	location          *time.Location             // 日志时间和文件切换使用的时区
+	rotationPolicy    CheckTimeToOpenNewFileFunc // 文件切换策略
+	errorHandler      func(err error)            // 写文件错误回调
+	overflowPolicy    *OverflowPolicy            // 缓冲队列满时的处理方式
+	neverDropLevel    *int                       // 不会被丢弃的最低等级
+	fatalFlushTimeout time.Duration              // Fatal日志退出前等待写完日志的时长
+	watchInterval     time.Duration              // 检查文件是否被移走的间隔, 0表示不检查
+	symlink           bool                       // 是否维护指向当前文件的软链接
+	format            Format                     // 输出格式
+	color             ColorMode                  // 屏幕输出是否带颜色
+	timeFormat        string                     // 时间格式
+	encoder           Encoder                    // 自定义编码, 设置后忽略format、color和timeFormat
+	fileEncoder       Encoder
+	consoleEncoder    Encoder         // 为nil时屏幕输出与文件相同
+	sinks             []SinkSpec      // 额外的输出目的地
+	errorFileLevel    *int            // 不为nil时该等级及以上的日志额外写入name.error文件
+	splitByLevel      bool            // 每个等级单独写一个文件
+	splitOnly         bool            // 按等级分文件时不再写主文件
+	module            string          // 模块名, 由Named创建的子logger才有
+	parent            *Logger         // 父logger, 子logger未单独设置等级时跟随父logger
+	modules           *moduleRegistry // 根logger及其所有子logger共享
+	rules             *levelRules     // 按包设置的等级, 根logger及其所有子logger共享
+	levelRulesSpec    *string         // WithLevelRules设置的规则
+	writer            *FileLoggerWriter
 }
 
 type ILogger interface {
@@ -35,12 +66,32 @@ type ILogger interface {
 }
 
 var (
-	instance          *logger
+	instance          atomic.Value // 默认logger, 包级别的日志函数都使用它
 	initMu            sync.Mutex
 	baseSkip          = 3  //跳过等级
 	globalSkipPkgPath bool // 跳过包路径 方法
 )
 
+var levelTags = [...]string{
+	TraceLevel: traceTag,
+	DebugLevel: debugTag,
+	InfoLevel:  infoTag,
+	WarnLevel:  warnTag,
+	ErrorLevel: errorTag,
+	StackLevel: stackTag,
+	FatalLevel: fatalTag,
+}
+
+var levelColors = [...]string{
+	TraceLevel: traceColor,
+	DebugLevel: debugColor,
+	InfoLevel:  infoColor,
+	WarnLevel:  warnColor,
+	ErrorLevel: errorColor,
+	StackLevel: stackColor,
+	FatalLevel: fatalColor,
+}
+
 type CallInfoSt struct {
 	File     string
 	Line     int
@@ -59,79 +110,217 @@ func (d *DefaultLogRequester) GetPrefix() string {
 	return ""
 }
 
-// SetLevel 设置日志级别
-func SetLevel(l int) {
-	if l > FatalLevel || l < TraceLevel {
+// Default 返回默认logger
+func Default() *Logger {
+	l, _ := instance.Load().(*Logger)
+	return l
+}
+
+// SetDefault 替换默认logger
+func SetDefault(l *Logger) {
+	if l == nil {
 		return
 	}
-	if nil != instance {
-		instance.level = l
+	initMu.Lock()
+	defer initMu.Unlock()
+	instance.Store(l)
+}
+
+// SetLevel 设置日志级别
+func SetLevel(l int) {
+	if ins := Default(); nil != ins {
+		ins.SetLevel(l)
 	}
 }
 
 func GetLevel() int {
-	return instance.level
+	return Default().GetLevel()
 }
 
 // SetMaxSize 设置日志切割大小
 func SetMaxSize(l int64) {
-	if nil != instance {
-		instance.maxFileSize = l
+	if ins := Default(); nil != ins {
+		ins.maxFileSize = l
 	}
 }
 
 // SetPerm 设置日志权限
 func SetPerm(l os.FileMode) {
-	if nil != instance {
-		instance.perm = l
+	if ins := Default(); nil != ins {
+		ins.perm = l
 	}
 }
 
+// New 创建一个独立的logger
+func New(opts ...Option) (*Logger, error) {
+	l := &Logger{modules: newModuleRegistry(), rules: newLevelRules()}
+	for _, opt := range opts {
+		opt(l)
+	}
+	// 显式设置的规则有误时返回错误, 只有环境变量中的规则才报告后忽略
+	if l.levelRulesSpec != nil {
+		if _, err := parseLevelRules(*l.levelRulesSpec); err != nil {
+			return nil, err
+		}
+	}
+	if err := l.init(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// InitLogger 初始化默认logger, 重复调用会在默认logger上追加配置
 func InitLogger(opts ...Option) ILogger {
 	initMu.Lock()
-	defer initMu.Unlock()
-
-	if nil == instance {
-		instance = &logger{}
+	ins := Default()
+	if nil == ins {
+		ins = &Logger{modules: newModuleRegistry(), rules: newLevelRules()}
 	}
 	for _, opt := range opts {
-		opt(instance)
+		opt(ins)
 	}
+	if err := ins.init(); err != nil {
+		fmt.Println("init logger failed:", err)
+	}
+	instance.Store(ins)
+	initMu.Unlock()
+
+	pID := os.Getpid()
+	pIDStr := strconv.FormatInt(int64(pID), 10)
+	LogInfo("===log:%v,pid:%v==logPath:%s==", ins.name, pIDStr, ins.path)
+
+	return ins
+}
 
+func (l *Logger) init() error {
 	//log文件夹不存在则先创建
-	if instance.path == "" {
+	if l.path == "" {
 		dir := os.Getenv("TLOGDIR")
 		if len(dir) > 0 {
-			instance.path = dir
+			l.path = dir
 		} else {
-			instance.path = DefaultLogPath
+			l.path = DefaultLogPath
 		}
 	}
 
-	if instance.maxFileSize == 0 {
-		instance.maxFileSize = LogFileMaxSize
+	if l.maxFileSize == 0 {
+		l.maxFileSize = LogFileMaxSize
 	}
 
-	if instance.perm == 0 {
-		instance.perm = fileMode
+	if l.perm == 0 {
+		l.perm = fileMode
 	}
 
-	if instance.writer == nil {
-		instance.writer = NewFileLoggerWriter(instance.path, instance.maxFileSize, 5, OpenNewFileByByDateHour, 100000, instance.perm)
+	l.configuredLevel = int(atomic.LoadInt32(&l.level))
 
-		go func() {
-			err := instance.writer.Loop()
-			if err != nil {
-				panic(err)
-			}
-		}()
+	// 环境变量只在首次初始化时读取, 避免重复InitLogger覆盖运行时设置的规则
+	// 规则有误时报告错误并忽略, 不影响创建logger; New会提前检查WithLevelRules设置的规则
+	if l.levelRulesSpec != nil || l.writer == nil {
+		spec := levelRulesFromEnv()
+		if l.levelRulesSpec != nil {
+			spec = *l.levelRulesSpec
+			l.levelRulesSpec = nil
+		}
+		if err := l.rules.set(spec); err != nil {
+			l.reportError(err)
+		}
 	}
 
-	pID := os.Getpid()
-	pIDStr := strconv.FormatInt(int64(pID), 10)
-	LogInfo("===log:%v,pid:%v==logPath:%s==", instance.name, pIDStr, instance.path)
+	l.consoleEncoder = nil
+	if l.encoder != nil {
+		l.fileEncoder = l.encoder
+	} else {
+		l.fileEncoder = l.format.encoder(false, l.timeFormat)
+		if l.format == TextFormat && l.color.enabled() {
+			l.consoleEncoder = l.format.encoder(true, l.timeFormat)
+		}
+	}
+
+	if l.rotationPolicy == nil {
+		period := LegacyDailyPeriod
+		if l.rotationPeriod != nil {
+			period = *l.rotationPeriod
+		}
+		l.rotationPolicy = NewRotationPolicy(l.name, period, l.location, nil)
+	}
+
+	if l.writer != nil {
+		return nil
+	}
+
+	// 目录创建失败也要创建writer, 由writer重试并在失败时改写到stderr
+	if err := os.MkdirAll(l.path, l.perm); err != nil {
+		l.reportError(err)
+	}
+
+	policy := l.rotationPolicy
+	l.writer = l.newFileWriter(l.name, policy)
+	if l.splitByLevel {
+		for lv := TraceLevel; lv <= FatalLevel; lv++ {
+			name := l.name + "." + levelNames[lv]
+			writer := l.newFileWriter(name, renamePolicy(policy, l.name, "."+levelNames[lv]))
+			l.sinks = append(l.sinks, SinkSpec{Sink: levelOnlySink{Sink: NewFileSink(writer), level: lv}, Level: lv})
+		}
+	} else if l.errorFileLevel != nil {
+		name := l.name + errorFileSuffix
+		writer := l.newFileWriter(name, renamePolicy(policy, l.name, errorFileSuffix))
+		l.sinks = append(l.sinks, SinkSpec{Sink: NewFileSink(writer), Level: *l.errorFileLevel})
+	}
 
-	return instance
+	// Loop只会在Close后退出, 错误由Close返回
+	go l.writer.Loop()
+	return nil
+}
+
+// newFileWriter 按logger的配置创建writer, name为文件名前缀
+func (l *Logger) newFileWriter(name string, policy CheckTimeToOpenNewFileFunc) *FileLoggerWriter {
+	writerOpts := []WriterOption{
+		WithWriterFilePrefix(name),
+		WithWriterMaxBackups(l.maxBackups),
+		WithWriterMaxAge(l.maxAge),
+		WithWriterCompression(l.compression),
+		WithWriterNoticeFormatter(l.formatNotice),
+	}
+	if l.errorHandler != nil {
+		writerOpts = append(writerOpts, WithWriterErrorHandler(l.errorHandler))
+	}
+	if l.overflowPolicy != nil {
+		writerOpts = append(writerOpts, WithWriterOverflowPolicy(*l.overflowPolicy))
+	}
+	if l.neverDropLevel != nil {
+		writerOpts = append(writerOpts, WithWriterNeverDropLevel(*l.neverDropLevel))
+	}
+	if l.location != nil {
+		writerOpts = append(writerOpts, WithWriterTimeZone(l.location))
+	}
+	if l.watchInterval > 0 {
+		writerOpts = append(writerOpts, WithWriterWatchFile(l.watchInterval))
+	}
+	if l.symlink {
+		writerOpts = append(writerOpts, WithWriterSymlink(name+".log"))
+	}
+	return NewFileLoggerWriter(l.path, l.maxFileSize, 5, policy, 100000, l.perm, writerOpts...)
+}
+
+// SetLevel 设置日志级别, 可在运行时调用
+func (l *Logger) SetLevel(lv int) {
+	if lv > FatalLevel || lv < TraceLevel {
+		return
+	}
+	atomic.StoreInt32(&l.level, int32(lv))
+}
+
+// GetLevel 当前生效的日志级别, 子logger未单独设置时返回父logger的级别
+func (l *Logger) GetLevel() int {
+	lv := atomic.LoadInt32(&l.level)
+	if lv == levelInherit && l.parent != nil {
+		return l.parent.GetLevel()
+	}
+	return int(lv)
+}
+
+func (l *Logger) enabled(lv int) bool {
+	return lv >= l.GetLevel()
 }
 
 func getPackageName(f string) (filePath string, fileFunc string) {
@@ -167,16 +356,15 @@ func GetCallInfo(skip int) *CallInfoSt {
 	}
 }
 
-func Flush() {
-	instance.writer.Flush()
+func buildTimeInfo(t time.Time) string {
+	return t.Format(TimeFormatDefault)
 }
 
-func buildTimeInfo() string {
-	return time.Now().Format("01-02 15:04:05.9999")
+func buildContent(format string, v ...interface{}) string {
+	return limitContent(fmt.Sprintf(format, v...))
 }
 
-func buildContent(format string, v ...interface{}) string {
-	content := fmt.Sprintf(format, v...)
+func limitContent(content string) string {
 	// protect disk
 	if size := utf8.RuneCountInString(content); size > 15000 {
 		content = string([]rune(content)[:15000]) + "..."
@@ -197,7 +385,7 @@ func buildCallInfo(call *CallInfoSt) string {
 	return fmt.Sprintf("%s:%d %s", call.File, call.Line, call.FuncName)
 }
 
-func buildRecord(curLv int, colorInfo, timeInfo, callerInfo, prefix, content string) string {
+func buildRecord(curLv int, colorInfo, timeInfo, callerInfo, prefix, content string, fields []Field, stack string) string {
 	var builder strings.Builder
 
 	header := fmt.Sprintf("%s %s [%s] ", timeInfo, prefix, callerInfo)
@@ -205,329 +393,330 @@ func buildRecord(curLv int, colorInfo, timeInfo, callerInfo, prefix, content str
 	builder.WriteString(fmt.Sprintf(colorInfo, header))
 
 	builder.WriteString(content)
+	builder.WriteString(buildFields(fields))
 	if curLv >= StackLevel {
 		builder.WriteString("\n")
-		builder.WriteString(buildStackInfo())
+		builder.WriteString(stack)
 	}
 
 	builder.WriteString("\n")
 	return builder.String()
 }
 
+func Flush() {
+	Default().Flush()
+}
+
+// Health 返回默认logger的writer健康状态
+func Health() WriterHealth {
+	return Default().Health()
+}
+
+// Dropped 返回默认logger因队列满被丢弃的日志统计
+func Dropped() DropStats {
+	return Default().Dropped()
+}
+
+// Reopen 重新打开默认logger的所有日志文件
+func Reopen() error {
+	return Default().Reopen()
+}
+
+// Close 关闭默认logger
+func Close(ctx context.Context) error {
+	return Default().Close(ctx)
+}
+
 // LogTrace 跟踪类型日志
 func LogTrace(format string, v ...interface{}) {
-	instance.LogTrace(format, v...)
+	Default().logf(TraceLevel, format, v...)
 }
 
 // LogDebug 调试类型日志
 func LogDebug(format string, v ...interface{}) {
-	instance.LogDebug(format, v...)
+	Default().logf(DebugLevel, format, v...)
 }
 
 // LogWarn 警告类型日志
 func LogWarn(format string, v ...interface{}) {
-	instance.LogWarn(format, v...)
+	Default().logf(WarnLevel, format, v...)
 }
 
 // LogInfo 程序信息类型日志
 func LogInfo(format string, v ...interface{}) {
-	instance.LogInfo(format, v...)
+	Default().logf(InfoLevel, format, v...)
 }
 
 // LogError 错误类型日志
 func LogError(format string, v ...interface{}) {
-	instance.LogError(format, v...)
+	Default().logf(ErrorLevel, format, v...)
 }
 
 // LogStack 堆栈debug日志
 func LogStack(format string, v ...interface{}) {
-	instance.LogStack(format, v...)
+	Default().logf(StackLevel, format, v...)
 }
 
 // LogFatal 致命错误类型日志
 func LogFatal(format string, v ...interface{}) {
-	instance.LogFatal(format, v...)
+	Default().fatalf(format, v...)
 }
 
 // LogTraceWithRequester 跟踪类型日志
 func LogTraceWithRequester(requester IRequester, format string, v ...interface{}) {
-	instance.LogTraceWithRequester(requester, format, v...)
+	Default().logfWithRequester(TraceLevel, requester, format, v...)
 }
 
 // LogDebugWithRequester 调试类型日志
 func LogDebugWithRequester(requester IRequester, format string, v ...interface{}) {
-	instance.LogDebugWithRequester(requester, format, v...)
+	Default().logfWithRequester(DebugLevel, requester, format, v...)
 }
 
 // LogWarnWithRequester 警告类型日志
 func LogWarnWithRequester(requester IRequester, format string, v ...interface{}) {
-	instance.LogWarnWithRequester(requester, format, v...)
+	Default().logfWithRequester(WarnLevel, requester, format, v...)
 }
 
 // InfoWithRequester 程序信息类型日志
 func LogInfoWithRequester(requester IRequester, format string, v ...interface{}) {
-	instance.LogInfoWithRequester(requester, format, v...)
+	Default().logfWithRequester(InfoLevel, requester, format, v...)
 }
 
 func LogErrorWithRequesterAndCustomCallInfo(requester IRequester, callInfo *CallInfoSt, format string, v ...interface{}) {
-	instance.LogErrorWithRequesterAndCustomCallInfo(requester, callInfo, format, v...)
+	Default().LogErrorWithRequesterAndCustomCallInfo(requester, callInfo, format, v...)
 }
 
 // LogErrorWithRequester 错误类型日志
 func LogErrorWithRequester(requester IRequester, format string, v ...interface{}) {
-	instance.LogErrorWithRequester(requester, format, v...)
+	Default().logfWithRequester(ErrorLevel, requester, format, v...)
 }
 
 // LogStackWithRequester 堆栈debug日志
 func LogStackWithRequester(requester IRequester, format string, v ...interface{}) {
-	instance.LogStackWithRequester(requester, format, v...)
+	Default().logfWithRequester(StackLevel, requester, format, v...)
 }
 
 // LogFatalWithRequester 致命错误类型日志
 func LogFatalWithRequester(requester IRequester, format string, v ...interface{}) {
-	instance.LogFatalWithRequester(requester, format, v...)
+	Default().fatalfWithRequester(requester, format, v...)
 }
 
-// LogTraceWithRequester 跟踪类型日志
-func (l *logger) LogTraceWithRequester(requester IRequester, format string, v ...interface{}) {
-	if l.level > TraceLevel {
-		return
+// output 组装并写出一条日志, 返回日志内容
+func (l *Logger) output(lv int, callInfo *CallInfoSt, content string, fields []Field) string {
+	r := &Record{
+		Time:    l.now(),
+		Level:   lv,
+		App:     l.name,
+		Prefix:  l.prefix,
+		Caller:  callInfo,
+		Module:  l.module,
+		Message: content,
+		Fields:  fields,
 	}
-
-	prefix := requester.GetLogPrefix()
-	format = prefix + format
-	callInfo := GetCallInfo(requester.GetLogCallStackSkip() + baseSkip)
-	record := buildRecord(TraceLevel, traceColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
-
-	if l.bScreen {
-		fmt.Printf("%s", record)
+	if lv >= StackLevel {
+		r.Stack = buildStackInfo()
 	}
-}
-
-// LogDebugWithRequester 调试类型日志
-func (l *logger) LogDebugWithRequester(requester IRequester, format string, v ...interface{}) {
-	if l.level > DebugLevel {
-		return
+	record := encodeRecord(l.fileEncoder, r)
+	if !l.splitOnly {
+		l.writer.WriteLevel(lv, record)
 	}
 
-	prefix := requester.GetLogPrefix()
-	format = prefix + format
-	callInfo := GetCallInfo(requester.GetLogCallStackSkip() + baseSkip)
-	record := buildRecord(DebugLevel, debugColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
-
 	if l.bScreen {
-		fmt.Printf("%s", record)
+		screen := record
+		if l.consoleEncoder != nil {
+			screen = encodeRecord(l.consoleEncoder, r)
+		}
+		fmt.Printf("%s", screen)
 	}
+
+	l.writeSinks(r, record)
+	return record
 }
 
-// LogWarnWithRequester 警告类型日志
-func (l *logger) LogWarnWithRequester(requester IRequester, format string, v ...interface{}) {
-	if l.level > WarnLevel {
-		return
+// now 日志时间, 按location转换时区
+func (l *Logger) now() time.Time {
+	if l.location != nil {
+		return time.Now().In(l.location)
 	}
+	return time.Now()
+}
 
-	prefix := requester.GetLogPrefix()
-	format = prefix + format
-
-	callInfo := GetCallInfo(requester.GetLogCallStackSkip() + baseSkip)
-	record := buildRecord(WarnLevel, warnColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
-
-	if l.bScreen {
-		fmt.Printf("%s", record)
-	}
+// formatNotice 按logger的输出格式编码writer自身的提示信息
+func (l *Logger) formatNotice(lv int, msg string) string {
+	return encodeRecord(l.fileEncoder, &Record{
+		Time:    l.now(),
+		Level:   lv,
+		App:     l.name,
+		Prefix:  l.prefix,
+		Message: msg,
+	})
 }
 
-// InfoWithRequester 程序信息类型日志
-func (l *logger) LogInfoWithRequester(requester IRequester, format string, v ...interface{}) {
-	if l.level > InfoLevel {
+// logf 调用链固定为 调用方->日志函数->logf, 包级别函数和方法都直接调用它以保证调用栈深度一致
+func (l *Logger) logf(lv int, format string, v ...interface{}) {
+	if !l.enabledAt(lv, baseSkip) {
 		return
 	}
-
-	prefix := requester.GetLogPrefix()
-	format = prefix + format
-
-	callInfo := GetCallInfo(requester.GetLogCallStackSkip() + baseSkip)
-	record := buildRecord(InfoLevel, infoColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
-
-	if l.bScreen {
-		fmt.Printf("%s", record)
-	}
+	l.output(lv, GetCallInfo(baseSkip), buildContent(format, v...), nil)
 }
 
-func (l *logger) LogErrorWithRequesterAndCustomCallInfo(requester IRequester, callInfo *CallInfoSt, format string, v ...interface{}) {
-	if l.level > ErrorLevel {
+// logw 结构化日志, 调用栈深度与logf一致
+func (l *Logger) logw(lv int, msg string, fields []Field) {
+	if !l.enabledAt(lv, baseSkip) {
 		return
 	}
-
-	prefix := requester.GetLogPrefix()
-	format = prefix + format
-
-	record := buildRecord(ErrorLevel, errorColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
-
-	if l.bScreen {
-		fmt.Printf("%s", record)
-	}
+	l.output(lv, GetCallInfo(baseSkip), limitContent(msg), fields)
 }
 
-// LogErrorWithRequester 错误类型日志
-func (l *logger) LogErrorWithRequester(requester IRequester, format string, v ...interface{}) {
-	if l.level > ErrorLevel {
+func (l *Logger) logfWithRequester(lv int, requester IRequester, format string, v ...interface{}) {
+	skip := requester.GetLogCallStackSkip() + baseSkip
+	if !l.enabledAt(lv, skip) {
 		return
 	}
+	callInfo := GetCallInfo(skip)
+	l.output(lv, callInfo, buildContent(requester.GetLogPrefix()+format, v...), nil)
+}
 
-	prefix := requester.GetLogPrefix()
-	format = prefix + format
-
-	callInfo := GetCallInfo(requester.GetLogCallStackSkip() + baseSkip)
-	record := buildRecord(ErrorLevel, errorColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
-
-	if l.bScreen {
-		fmt.Printf("%s", record)
-	}
+func (l *Logger) fatalf(format string, v ...interface{}) {
+	l.fatal(GetCallInfo(baseSkip), buildContent(format, v...), nil)
 }
 
-// LogStackWithRequester 堆栈debug日志
-func (l *logger) LogStackWithRequester(requester IRequester, format string, v ...interface{}) {
-	if l.level > StackLevel {
-		return
-	}
+func (l *Logger) fatalw(msg string, fields []Field) {
+	l.fatal(GetCallInfo(baseSkip), limitContent(msg), fields)
+}
 
-	prefix := requester.GetLogPrefix()
-	format = prefix + format
+func (l *Logger) fatal(callInfo *CallInfoSt, content string, fields []Field) {
+	record := l.output(FatalLevel, callInfo, content, fields)
 
-	callInfo := GetCallInfo(requester.GetLogCallStackSkip() + baseSkip)
-	record := buildRecord(StackLevel, stackColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
+	dir, _ := filepath.Abs(filepath.Dir(os.Args[0]))
+	tf := time.Now()
+	os.WriteFile(fmt.Sprintf("%s/core-%s.%02d%02d-%02d%02d%02d.panic", dir, l.name, tf.Month(), tf.Day(), tf.Hour(), tf.Minute(), tf.Second()), []byte(record), fileMode)
 
-	if l.bScreen {
-		fmt.Printf("%s", record)
-	}
+	l.exit()
 }
 
-// LogFatalWithRequester 致命错误类型日志
-func (l *logger) LogFatalWithRequester(requester IRequester, format string, v ...interface{}) {
-	prefix := requester.GetLogPrefix()
-	format = prefix + format
-
+func (l *Logger) fatalfWithRequester(requester IRequester, format string, v ...interface{}) {
 	callInfo := GetCallInfo(requester.GetLogCallStackSkip() + baseSkip)
-	content := buildContent(format, v...)
-	record := buildRecord(FatalLevel, fatalColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, content)
-	l.writer.Write(record)
+	l.output(FatalLevel, callInfo, buildContent(requester.GetLogPrefix()+format, v...), nil)
+	l.exit()
+}
 
-	if l.bScreen {
-		fmt.Printf("%s", record)
-	}
-	os.Exit(1)
+// LogTraceWithRequester 跟踪类型日志
+func (l *Logger) LogTraceWithRequester(requester IRequester, format string, v ...interface{}) {
+	l.logfWithRequester(TraceLevel, requester, format, v...)
 }
 
-func (l *logger) LogWarn(format string, v ...interface{}) {
-	if l.level > WarnLevel {
-		return
-	}
+// LogDebugWithRequester 调试类型日志
+func (l *Logger) LogDebugWithRequester(requester IRequester, format string, v ...interface{}) {
+	l.logfWithRequester(DebugLevel, requester, format, v...)
+}
 
-	callInfo := GetCallInfo(baseSkip)
-	record := buildRecord(WarnLevel, warnColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
+// LogWarnWithRequester 警告类型日志
+func (l *Logger) LogWarnWithRequester(requester IRequester, format string, v ...interface{}) {
+	l.logfWithRequester(WarnLevel, requester, format, v...)
+}
 
-	if l.bScreen {
-		fmt.Printf("%s", record)
-	}
+// InfoWithRequester 程序信息类型日志
+func (l *Logger) LogInfoWithRequester(requester IRequester, format string, v ...interface{}) {
+	l.logfWithRequester(InfoLevel, requester, format, v...)
 }
 
-func (l *logger) LogInfo(format string, v ...interface{}) {
-	if l.level > InfoLevel {
+func (l *Logger) LogErrorWithRequesterAndCustomCallInfo(requester IRequester, callInfo *CallInfoSt, format string, v ...interface{}) {
+	if !l.enabled(ErrorLevel) {
 		return
 	}
+	l.output(ErrorLevel, callInfo, buildContent(requester.GetLogPrefix()+format, v...), nil)
+}
 
-	callInfo := GetCallInfo(baseSkip)
-	record := buildRecord(InfoLevel, infoColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
-
-	if l.bScreen {
-		fmt.Printf("%s", record)
-	}
+// LogErrorWithRequester 错误类型日志
+func (l *Logger) LogErrorWithRequester(requester IRequester, format string, v ...interface{}) {
+	l.logfWithRequester(ErrorLevel, requester, format, v...)
 }
 
-func (l *logger) LogError(format string, v ...interface{}) {
-	if l.level > ErrorLevel {
-		return
-	}
+// LogStackWithRequester 堆栈debug日志
+func (l *Logger) LogStackWithRequester(requester IRequester, format string, v ...interface{}) {
+	l.logfWithRequester(StackLevel, requester, format, v...)
+}
 
-	callInfo := GetCallInfo(baseSkip)
-	record := buildRecord(ErrorLevel, errorColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
+// LogFatalWithRequester 致命错误类型日志
+func (l *Logger) LogFatalWithRequester(requester IRequester, format string, v ...interface{}) {
+	l.fatalfWithRequester(requester, format, v...)
+}
 
-	if l.bScreen {
-		fmt.Printf("%s", record)
-	}
+func (l *Logger) LogWarn(format string, v ...interface{}) {
+	l.logf(WarnLevel, format, v...)
 }
 
-func (l *logger) LogFatal(format string, v ...interface{}) {
-	callInfo := GetCallInfo(baseSkip)
-	content := buildContent(format, v...)
-	record := buildRecord(FatalLevel, fatalColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, content)
-	l.writer.Write(record)
+func (l *Logger) LogInfo(format string, v ...interface{}) {
+	l.logf(InfoLevel, format, v...)
+}
 
-	dir, _ := filepath.Abs(filepath.Dir(os.Args[0]))
-	tf := time.Now()
-	os.WriteFile(fmt.Sprintf("%s/core-%s.%02d%02d-%02d%02d%02d.panic", dir, l.name, tf.Month(), tf.Day(), tf.Hour(), tf.Minute(), tf.Second()), []byte(record), fileMode)
+func (l *Logger) LogError(format string, v ...interface{}) {
+	l.logf(ErrorLevel, format, v...)
+}
 
-	if l.bScreen {
-		fmt.Printf("%s", record)
-	}
+func (l *Logger) LogFatal(format string, v ...interface{}) {
+	l.fatalf(format, v...)
+}
 
-	os.Exit(1)
+func (l *Logger) LogDebug(format string, v ...interface{}) {
+	l.logf(DebugLevel, format, v...)
 }
 
-func (l *logger) LogDebug(format string, v ...interface{}) {
-	if l.level > DebugLevel {
-		return
-	}
+func (l *Logger) LogStack(format string, v ...interface{}) {
+	l.logf(StackLevel, format, v...)
+}
 
-	callInfo := GetCallInfo(baseSkip)
-	record := buildRecord(DebugLevel, debugColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
+func (l *Logger) LogTrace(format string, v ...interface{}) {
+	l.logf(TraceLevel, format, v...)
+}
 
-	if l.bScreen {
-		fmt.Printf("%s", record)
+// Flush 写完并同步主文件和所有文件输出中缓冲的日志
+func (l *Logger) Flush() {
+	for _, w := range l.fileWriters() {
+		w.Flush()
 	}
 }
 
-func (l *logger) LogStack(format string, v ...interface{}) {
-	if l.level > StackLevel {
-		return
+// Health 返回所有文件writer合并后的健康状态
+func (l *Logger) Health() WriterHealth {
+	var health WriterHealth
+	for _, w := range l.fileWriters() {
+		health = health.merge(w.Health())
 	}
+	return health
+}
 
-	callInfo := GetCallInfo(baseSkip)
-	record := buildRecord(StackLevel, stackColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
-
-	if l.bScreen {
-		fmt.Printf("%s", record)
+// Dropped 返回所有文件writer因队列满被丢弃的日志统计之和
+func (l *Logger) Dropped() DropStats {
+	var stats DropStats
+	for _, w := range l.fileWriters() {
+		stats.add(w.Dropped())
 	}
+	return stats
 }
 
-func (l *logger) LogTrace(format string, v ...interface{}) {
-	if l.level > TraceLevel {
-		return
+// Close 关闭logger, 写完所有缓冲中的日志后返回, ctx超时则提前返回
+func (l *Logger) Close(ctx context.Context) error {
+	// 子logger与根logger共用输出, 由根logger关闭
+	if l.parent != nil {
+		return nil
 	}
-
-	callInfo := GetCallInfo(baseSkip)
-	record := buildRecord(TraceLevel, traceColor, buildTimeInfo(), buildCallInfo(callInfo), l.prefix, buildContent(format, v...))
-	l.writer.Write(record)
-	if l.bScreen {
-		fmt.Printf("%s", record)
+	err := l.writer.Close(ctx)
+	if sinkErr := l.closeSinks(ctx); err == nil {
+		err = sinkErr
 	}
+	return err
 }
 
-func (l *logger) Flush() {
-	l.writer.Flush()
+// Reopen 重新打开所有日志文件, 子logger调用时作用于根logger
+func (l *Logger) Reopen() error {
+	l = l.root()
+	err := l.writer.Reopen()
+	if sinkErr := l.reopenSinks(); err == nil {
+		err = sinkErr
+	}
+	return err
 }
 
 func SetGlobalSkipFilePath() {
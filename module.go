@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// levelInherit 子logger未单独设置等级, 跟随父logger
+const levelInherit = -1
+
+// moduleRegistry 按模块名登记由Named创建的子logger
+type moduleRegistry struct {
+	mu      sync.RWMutex
+	modules map[string]*Logger
+}
+
+func newModuleRegistry() *moduleRegistry {
+	return &moduleRegistry{modules: make(map[string]*Logger)}
+}
+
+// Named 创建名为name的子logger, 输出与父logger相同, 每条日志带上模块名
+// 子logger默认跟随父logger的等级, 调用SetLevel后使用自己的等级
+// 嵌套调用时模块名用.连接, 如battle.ai; 同名模块只会创建一次
+func (l *Logger) Named(name string) *Logger {
+	module := name
+	if l.module != "" {
+		module = l.module + "." + name
+	}
+
+	l.modules.mu.Lock()
+	defer l.modules.mu.Unlock()
+	if child, ok := l.modules.modules[module]; ok {
+		return child
+	}
+
+	child := *l
+	child.module = module
+	child.parent = l
+	child.level = levelInherit
+	l.modules.modules[module] = &child
+	return &child
+}
+
+// Module 返回已创建的子logger
+func (l *Logger) Module(module string) (*Logger, bool) {
+	l.modules.mu.RLock()
+	defer l.modules.mu.RUnlock()
+	child, ok := l.modules.modules[module]
+	return child, ok
+}
+
+// SetModuleLevel 设置子logger的等级, 模块不存在时返回false
+func (l *Logger) SetModuleLevel(module string, lv int) bool {
+	child, ok := l.Module(module)
+	if !ok {
+		return false
+	}
+	child.SetLevel(lv)
+	return true
+}
+
+// ResetModuleLevel 子logger恢复跟随父logger的等级
+func (l *Logger) ResetModuleLevel(module string) bool {
+	child, ok := l.Module(module)
+	if !ok {
+		return false
+	}
+	child.resetLevel()
+	return true
+}
+
+func (l *Logger) resetLevel() {
+	if l.parent == nil {
+		return
+	}
+	atomic.StoreInt32(&l.level, levelInherit)
+}
+
+// root 根logger, 负责关闭共用的输出
+func (l *Logger) root() *Logger {
+	for l.parent != nil {
+		l = l.parent
+	}
+	return l
+}
+
+// ModuleLevels 所有子logger当前生效的等级
+func (l *Logger) ModuleLevels() map[string]int {
+	l.modules.mu.RLock()
+	defer l.modules.mu.RUnlock()
+	levels := make(map[string]int, len(l.modules.modules))
+	for module, child := range l.modules.modules {
+		levels[module] = child.GetLevel()
+	}
+	return levels
+}
+
+// ModuleNames 按名字排序的模块列表
+func (l *Logger) ModuleNames() []string {
+	l.modules.mu.RLock()
+	defer l.modules.mu.RUnlock()
+	names := make([]string, 0, len(l.modules.modules))
+	for module := range l.modules.modules {
+		names = append(names, module)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Named 创建默认logger的子logger
+func Named(name string) *Logger {
+	return Default().Named(name)
+}
+
+// SetModuleLevel 设置默认logger下子logger的等级
+func SetModuleLevel(module string, lv int) bool {
+	return Default().SetModuleLevel(module, lv)
+}
+
+// ModuleLevels 默认logger下所有子logger当前生效的等级
+func ModuleLevels() map[string]int {
+	return Default().ModuleLevels()
+}
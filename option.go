@@ -1,47 +1,278 @@
 package logger
 
-import "os"
+import (
+	"os"
+	"time"
+)
 
-type Option func(log *logger)
+type Option func(log *Logger)
 
 func WithAppName(name string) Option {
-	return func(log *logger) {
+	return func(log *Logger) {
 		log.name = name
 	}
 }
 
 func WithPath(path string) Option {
-	return func(log *logger) {
+	return func(log *Logger) {
 		log.path = path
 	}
 }
 
 func WithLevel(level int) Option {
-	return func(log *logger) {
-		log.level = level
+	return func(log *Logger) {
+		log.level = int32(level)
 	}
 }
 
 func WithScreen(flag bool) Option {
-	return func(log *logger) {
+	return func(log *Logger) {
 		log.bScreen = flag
 	}
 }
 
 func WithPrefix(prefix string) Option {
-	return func(log *logger) {
+	return func(log *Logger) {
 		log.prefix = prefix
 	}
 }
 
 func WithPerm(perm os.FileMode) Option {
-	return func(log *logger) {
+	return func(log *Logger) {
 		log.perm = perm
 	}
 }
 
 func WithFileMaxSize(size int64) Option {
-	return func(log *logger) {
+	return func(log *Logger) {
 		log.maxFileSize = size
 	}
 }
+
+// WithMaxBackups 保留的历史日志文件个数, 0表示不限制
+func WithMaxBackups(n int) Option {
+	return func(log *Logger) {
+		log.maxBackups = n
+	}
+}
+
+// WithMaxAge 历史日志文件保留时长, 0表示不限制
+func WithMaxAge(d time.Duration) Option {
+	return func(log *Logger) {
+		log.maxAge = d
+	}
+}
+
+// WithCompression 历史日志文件在后台压缩, 如GzipCompression
+func WithCompression(c *Compression) Option {
+	return func(log *Logger) {
+		log.compression = c
+	}
+}
+
+// WithRotationPeriod 日志文件切换周期, 默认LegacyDailyPeriod
+func WithRotationPeriod(period RotationPeriod) Option {
+	return func(log *Logger) {
+		log.rotationPeriod = &period
+	}
+}
+
+// WithTimeZone 日志时间和切换文件使用的时区, 默认本地时区, 如time.UTC或time.FixedZone("CST", 8*3600)
+func WithTimeZone(loc *time.Location) Option {
+	return func(log *Logger) {
+		log.location = loc
+	}
+}
+
+// WithRotationPolicy 自定义日志文件切换策略, 设置后忽略WithRotationPeriod
+// 按周期切换时请使用WithRotationPeriod, 旧的OpenNewFile*策略使用默认logger的名字
+func WithRotationPolicy(policy CheckTimeToOpenNewFileFunc) Option {
+	return func(log *Logger) {
+		log.rotationPolicy = policy
+	}
+}
+
+// WithErrorHandler 写日志文件出错时的回调, 默认输出到stderr
+// 回调可能在writer的Loop协程中执行, 不要在其中同步写入同一个logger不会被丢弃的日志(默认Error及以上), 队列满时会死锁
+func WithErrorHandler(handler func(err error)) Option {
+	return func(log *Logger) {
+		log.errorHandler = handler
+	}
+}
+
+// WithFatalFlushTimeout Fatal日志退出进程前等待写完日志的最长时间, 退出钩子另外最多等待同样时长, 默认DefaultFatalFlushTimeout
+func WithFatalFlushTimeout(d time.Duration) Option {
+	return func(log *Logger) {
+		log.fatalFlushTimeout = d
+	}
+}
+
+// WithOverflowPolicy 日志缓冲队列满时的处理方式, 默认OverflowDropNewest
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(log *Logger) {
+		log.overflowPolicy = &policy
+	}
+}
+
+// WithNeverDropLevel 不低于该等级的日志在队列满时阻塞等待, 不会被丢弃, 默认DefaultNeverDropLevel
+func WithNeverDropLevel(level int) Option {
+	return func(log *Logger) {
+		log.neverDropLevel = &level
+	}
+}
+
+// WithFormat 日志输出格式, 默认TextFormat
+func WithFormat(format Format) Option {
+	return func(log *Logger) {
+		log.format = format
+	}
+}
+
+// WithColor 屏幕输出的颜色模式, 默认ColorAuto
+func WithColor(mode ColorMode) Option {
+	return func(log *Logger) {
+		log.color = mode
+	}
+}
+
+// WithEncoder 自定义日志编码, 如NewTemplateEncoder, 设置后忽略WithFormat和WithColor
+func WithEncoder(enc Encoder) Option {
+	return func(log *Logger) {
+		log.encoder = enc
+	}
+}
+
+// WithTimeFormat 日志时间格式, 如TimeFormatDateTime、TimeFormatRFC3339Nano、TimeFormatEpochMillis
+func WithTimeFormat(layout string) Option {
+	return func(log *Logger) {
+		log.timeFormat = layout
+	}
+}
+
+// WithSinks 额外的输出目的地, 每个目的地有自己的最低等级和编码
+func WithSinks(sinks ...SinkSpec) Option {
+	return func(log *Logger) {
+		log.sinks = append(log.sinks, sinks...)
+	}
+}
+
+// WithErrorFile 不低于level的日志额外写入name.error文件, 切换和清理规则与主文件相同
+func WithErrorFile(level int) Option {
+	return func(log *Logger) {
+		log.errorFileLevel = &level
+	}
+}
+
+// WithSplitByLevel 每个等级单独写入name.<level>文件, 如name.debug.01-02.log, keepMain为false时不再写主文件
+// 开启后忽略WithErrorFile
+func WithSplitByLevel(keepMain bool) Option {
+	return func(log *Logger) {
+		log.splitByLevel = true
+		log.splitOnly = !keepMain
+	}
+}
+
+// WithLevelRules 按包设置日志等级, 格式见SetLevelRules, 不设置时读取环境变量TLOGLEVELS
+// 规则有误时New返回错误, 环境变量中的规则有误时只报告错误并忽略
+func WithLevelRules(spec string) Option {
+	return func(log *Logger) {
+		log.levelRulesSpec = &spec
+	}
+}
+
+// WithWatchFile 每隔interval检查当前文件是否被移走或删除, 是则重新打开, 用于配合外部logrotate
+func WithWatchFile(interval time.Duration) Option {
+	return func(log *Logger) {
+		log.watchInterval = interval
+	}
+}
+
+// WithSymlink 维护name.log指向当前文件的软链接, 如name.log -> name.10-17.log, 便于tail -F等工具使用固定路径
+func WithSymlink() Option {
+	return func(log *Logger) {
+		log.symlink = true
+	}
+}
+
+type WriterOption func(w *FileLoggerWriter)
+
+// WithWriterFilePrefix 日志文件名前缀, 用于识别属于该writer的历史文件, 不设置时从当前文件名推断
+func WithWriterFilePrefix(prefix string) WriterOption {
+	return func(w *FileLoggerWriter) {
+		w.filePrefix = prefix
+	}
+}
+
+func WithWriterMaxBackups(n int) WriterOption {
+	return func(w *FileLoggerWriter) {
+		w.maxBackups = n
+	}
+}
+
+func WithWriterMaxAge(d time.Duration) WriterOption {
+	return func(w *FileLoggerWriter) {
+		w.maxAge = d
+	}
+}
+
+func WithWriterCompression(c *Compression) WriterOption {
+	return func(w *FileLoggerWriter) {
+		w.compression = c
+	}
+}
+
+// WithWriterClock 设置writer的时钟, 需要与切换策略使用同一个时钟
+func WithWriterClock(clock func() time.Time) WriterOption {
+	return func(w *FileLoggerWriter) {
+		w.clock = clock
+	}
+}
+
+// WithWriterErrorHandler 写文件出错时的回调, 在Loop协程中执行, 不要在其中同步写入该writer不会被丢弃的日志
+func WithWriterErrorHandler(handler func(err error)) WriterOption {
+	return func(w *FileLoggerWriter) {
+		w.errorHandler = handler
+	}
+}
+
+func WithWriterOverflowPolicy(policy OverflowPolicy) WriterOption {
+	return func(w *FileLoggerWriter) {
+		w.overflowPolicy = policy
+	}
+}
+
+func WithWriterNeverDropLevel(level int) WriterOption {
+	return func(w *FileLoggerWriter) {
+		w.neverDropLevel = level
+	}
+}
+
+// WithWriterNoticeFormatter writer自身写入提示信息(如丢弃统计)时使用的格式
+func WithWriterNoticeFormatter(formatter func(level int, msg string) string) WriterOption {
+	return func(w *FileLoggerWriter) {
+		w.noticeFormatter = formatter
+	}
+}
+
+// WithWriterWatchFile 写日志时每隔interval检查当前文件是否被移走或删除, 是则重新打开
+func WithWriterWatchFile(interval time.Duration) WriterOption {
+	return func(w *FileLoggerWriter) {
+		w.watchInterval = interval
+	}
+}
+
+// WithWriterSymlink 每次打开或切换文件后把baseDir下的name软链接指向当前文件
+func WithWriterSymlink(name string) WriterOption {
+	return func(w *FileLoggerWriter) {
+		w.symlinkName = name
+	}
+}
+
+// WithWriterTimeZone writer自身提示信息中时间使用的时区, 默认本地时区
+func WithWriterTimeZone(loc *time.Location) WriterOption {
+	return func(w *FileLoggerWriter) {
+		if loc != nil {
+			w.location = loc
+		}
+	}
+}
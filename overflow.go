@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"time"
+)
+
+const (
+	overflowDropNewest = iota
+	overflowDropOldest
+	overflowBlock
+)
+
+// OverflowPolicy 日志缓冲队列满时的处理方式
+type OverflowPolicy struct {
+	mode    int
+	timeout time.Duration // 阻塞等待的最长时间, 0表示一直等待
+}
+
+var (
+	// OverflowDropNewest 丢弃新日志, 默认策略
+	OverflowDropNewest = OverflowPolicy{mode: overflowDropNewest}
+	// OverflowDropOldest 丢弃队列中最早的日志, 队列中有不能丢弃的日志时丢弃新日志, 不打乱日志顺序
+	OverflowDropOldest = OverflowPolicy{mode: overflowDropOldest}
+	// OverflowBlock 一直阻塞直到有空位
+	OverflowBlock = OverflowPolicy{mode: overflowBlock}
+)
+
+// OverflowBlockWithTimeout 最多阻塞d, 超时后丢弃新日志
+func OverflowBlockWithTimeout(d time.Duration) OverflowPolicy {
+	return OverflowPolicy{mode: overflowBlock, timeout: d}
+}
+
+// DefaultNeverDropLevel 不低于该等级的日志在队列满时一直阻塞, 不会被丢弃
+const DefaultNeverDropLevel = ErrorLevel
+
+type bufEntry struct {
+	level int
+	data  []byte
+}
+
+func (w *FileLoggerWriter) isNeverDrop(level int) bool {
+	return level >= w.neverDropLevel
+}
+
+// enqueue 按溢出策略放入缓冲队列, 调用方需持有closeMu读锁
+// 需要阻塞等待时返回要放入的日志, 由调用方释放锁后调用enqueueBlocking, 避免Close等待读锁时无法超时返回
+func (w *FileLoggerWriter) enqueue(entry bufEntry) (pending *bufEntry, timeout time.Duration) {
+	if w.trySend(entry) {
+		return nil, 0
+	}
+
+	if w.isNeverDrop(entry.level) {
+		return &entry, 0
+	}
+
+	switch w.overflowPolicy.mode {
+	case overflowDropOldest:
+		return w.enqueueDropOldest(entry), 0
+	case overflowBlock:
+		return &entry, w.overflowPolicy.timeout
+	default:
+		w.drop(entry)
+		return nil, 0
+	}
+}
+
+// trySend 不阻塞地放入队列, 不能丢弃的日志先计数再放入, 保证Loop取出时计数已经加上
+func (w *FileLoggerWriter) trySend(entry bufEntry) bool {
+	protected := w.isNeverDrop(entry.level)
+	if protected {
+		w.protectedQueued.Add(1)
+	}
+	select {
+	case w.bufCh <- entry:
+		return true
+	default:
+	}
+	if protected {
+		w.protectedQueued.Add(-1)
+	}
+	return false
+}
+
+// dequeued 从队列取出日志后调用
+func (w *FileLoggerWriter) dequeued(entry bufEntry) {
+	if w.isNeverDrop(entry.level) {
+		w.protectedQueued.Add(-1)
+	}
+}
+
+func (w *FileLoggerWriter) enqueueBlocking(entry bufEntry, timeout time.Duration) {
+	var timeoutCh <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	protected := w.isNeverDrop(entry.level)
+	if protected {
+		w.protectedQueued.Add(1)
+	}
+	select {
+	case w.bufCh <- entry:
+		return
+	case <-timeoutCh:
+	case <-w.doneCh:
+	}
+	if protected {
+		w.protectedQueued.Add(-1)
+	}
+	w.drop(entry)
+}
+
+// dropOldestRetries 与其他协程争抢空位的最多次数
+const dropOldestRetries = 3
+
+// enqueueDropOldest 队列中有不能丢弃的日志时直接丢弃新日志, 不取出队列中的日志, 以免打乱顺序
+// 返回需要阻塞放回队列的日志
+func (w *FileLoggerWriter) enqueueDropOldest(entry bufEntry) *bufEntry {
+	for i := 0; i < dropOldestRetries; i++ {
+		if w.trySend(entry) {
+			return nil
+		}
+		if w.protectedQueued.Load() > 0 {
+			break
+		}
+
+		select {
+		case oldest := <-w.bufCh:
+			w.dequeued(oldest)
+			if w.isNeverDrop(oldest.level) {
+				// 检查计数之后队列被取空又放入了不能丢弃的日志, 只能放回队尾
+				w.drop(entry)
+				return &oldest
+			}
+			w.drop(oldest)
+		default:
+		}
+	}
+	w.drop(entry)
+	return nil
+}
+
+// drop 只计数, 队列恢复后由Loop把丢弃情况写入日志文件
+func (w *FileLoggerWriter) drop(entry bufEntry) {
+	w.dropped.add(entry.level, len(entry.data))
+}
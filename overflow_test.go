@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newOverflowWriter 返回还没有启动Loop的writer, 队列满后的行为可以确定
+func newOverflowWriter(t *testing.T, policy OverflowPolicy, chanLen uint32) *FileLoggerWriter {
+	t.Helper()
+	w := NewFileLoggerWriter(t.TempDir(), 1<<20, 0, NewRotationPolicy("q", DailyPeriod, nil, nil), chanLen, 0755,
+		WithWriterOverflowPolicy(policy),
+		WithWriterNoticeFormatter(func(level int, msg string) string { return "dropped\n" }))
+	t.Cleanup(func() { w.Close(context.Background()) })
+	return w
+}
+
+// drainWriter 启动Loop写完队列中的日志并关闭, 返回文件内容
+func drainWriter(t *testing.T, w *FileLoggerWriter) string {
+	t.Helper()
+	go w.Loop()
+	return closeWriter(t, w)
+}
+
+func closeWriter(t *testing.T, w *FileLoggerWriter) string {
+	t.Helper()
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return readFile(t, filepath.Join(w.baseDir, w.activeFileName()))
+}
+
+type levelLine struct {
+	level int
+	line  string
+}
+
+func TestOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  OverflowPolicy
+		writes  []levelLine
+		want    string
+		dropped [levelCount]uint64
+	}{
+		{
+			name:    "drop newest",
+			policy:  OverflowDropNewest,
+			writes:  []levelLine{{InfoLevel, "I1\n"}, {InfoLevel, "I2\n"}, {InfoLevel, "I3\n"}, {InfoLevel, "I4\n"}},
+			want:    "I1\nI2\nI3\ndropped\n",
+			dropped: [levelCount]uint64{InfoLevel: 1},
+		},
+		{
+			name:    "drop oldest",
+			policy:  OverflowDropOldest,
+			writes:  []levelLine{{InfoLevel, "I1\n"}, {InfoLevel, "I2\n"}, {InfoLevel, "I3\n"}, {InfoLevel, "I4\n"}},
+			want:    "I2\nI3\nI4\ndropped\n",
+			dropped: [levelCount]uint64{InfoLevel: 1},
+		},
+		{
+			// 队列中有不能丢弃的日志时丢弃新日志, 不打乱顺序
+			name:    "drop oldest keeps protected",
+			policy:  OverflowDropOldest,
+			writes:  []levelLine{{ErrorLevel, "E1\n"}, {InfoLevel, "I2\n"}, {InfoLevel, "I3\n"}, {InfoLevel, "I4\n"}},
+			want:    "E1\nI2\nI3\ndropped\n",
+			dropped: [levelCount]uint64{InfoLevel: 1},
+		},
+		{
+			name:    "block with timeout",
+			policy:  OverflowBlockWithTimeout(20 * time.Millisecond),
+			writes:  []levelLine{{InfoLevel, "I1\n"}, {InfoLevel, "I2\n"}, {InfoLevel, "I3\n"}, {DebugLevel, "D4\n"}},
+			want:    "I1\nI2\nI3\ndropped\n",
+			dropped: [levelCount]uint64{DebugLevel: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newOverflowWriter(t, tt.policy, 3)
+			for _, wr := range tt.writes {
+				w.WriteLevel(wr.level, wr.line)
+			}
+			if got := w.Dropped().Records; got != tt.dropped {
+				t.Errorf("dropped = %v, want %v", got, tt.dropped)
+			}
+			if got := drainWriter(t, w); got != tt.want {
+				t.Errorf("file = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOverflowBlocking 阻塞策略和不能丢弃的日志在队列满时等待Loop取走日志
+func TestOverflowBlocking(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		level  int
+		want   string
+	}{
+		{"block", OverflowBlock, InfoLevel, "I1\nX2\n"},
+		{"never drop", OverflowDropNewest, ErrorLevel, "I1\nX2\n"},
+		{"never drop with drop oldest", OverflowDropOldest, FatalLevel, "I1\nX2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newOverflowWriter(t, tt.policy, 1)
+			w.WriteLevel(InfoLevel, "I1\n")
+
+			written := make(chan struct{})
+			go func() {
+				w.WriteLevel(tt.level, "X2\n")
+				close(written)
+			}()
+			select {
+			case <-written:
+				t.Fatal("write returned while queue is full")
+			case <-time.After(30 * time.Millisecond):
+			}
+
+			// 等阻塞的日志放入队列后再关闭
+			go w.Loop()
+			<-written
+			if got := closeWriter(t, w); got != tt.want {
+				t.Errorf("file = %q, want %q", got, tt.want)
+			}
+			if got := w.Dropped().TotalRecords(); got != 0 {
+				t.Errorf("dropped = %d, want 0", got)
+			}
+		})
+	}
+}
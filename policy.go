@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RotationPeriod 日志文件切换周期
+type RotationPeriod struct {
+	layout string                      // 文件名后缀格式
+	start  func(t time.Time) time.Time // t所在周期的起始时间
+}
+
+func dayStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+var (
+	// HourlyPeriod 每小时一个文件, name.2006-01-02-15.log
+	HourlyPeriod = RotationPeriod{
+		layout: ".2006-01-02-15.log",
+		start: func(t time.Time) time.Time {
+			y, m, d := t.Date()
+			return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+		},
+	}
+	// DailyPeriod 每天一个文件, name.2006-01-02.log
+	DailyPeriod = RotationPeriod{
+		layout: ".2006-01-02.log",
+		start:  dayStart,
+	}
+	// WeeklyPeriod 每周一个文件, 以周一日期命名 name.2006-01-02.week.log
+	WeeklyPeriod = RotationPeriod{
+		layout: ".2006-01-02.week.log",
+		start: func(t time.Time) time.Time {
+			offset := (int(t.Weekday()) + 6) % 7
+			return dayStart(t).AddDate(0, 0, -offset)
+		},
+	}
+	// LegacyDailyPeriod 每天一个文件, 文件名不带年份 name.01-02.log
+	LegacyDailyPeriod = RotationPeriod{
+		layout: ".01-02.log",
+		start:  dayStart,
+	}
+)
+
+// EveryMinutesPeriod 每n分钟一个文件, name.2006-01-02-15-04.log
+func EveryMinutesPeriod(n int) RotationPeriod {
+	if n <= 0 {
+		n = 1
+	}
+	return RotationPeriod{
+		layout: ".2006-01-02-15-04.log",
+		// 每天从0点开始按墙上时间重新计算周期, 夏令时切换当天也按钟点对齐
+		start: func(t time.Time) time.Time {
+			y, m, d := t.Date()
+			minutes := t.Hour()*60 + t.Minute()
+			return time.Date(y, m, d, 0, minutes-minutes%n, 0, 0, t.Location())
+		},
+	}
+}
+
+// NewRotationPolicy 按周期切换文件的策略, 文件名为name加周期起始时间
+// loc为nil时使用本地时区, clock为nil时使用time.Now
+func NewRotationPolicy(name string, period RotationPeriod, loc *time.Location, clock func() time.Time) CheckTimeToOpenNewFileFunc {
+	if loc == nil {
+		loc = time.Local
+	}
+	if clock == nil {
+		clock = time.Now
+	}
+	return func(lastOpenFileTime *time.Time, isNeverOpenFile bool) (string, bool) {
+		nowStart := period.start(clock().In(loc))
+		if !isNeverOpenFile && period.start(lastOpenFileTime.In(loc)).Equal(nowStart) {
+			return "", false
+		}
+		return name + nowStart.Format(period.layout), true
+	}
+}
+
+// renamePolicy 在policy返回的文件名前缀后加上suffix, 用于同一套切换规则生成另一组文件
+// 如name.01-02.log -> name.error.01-02.log, 文件名不以name开头时按splitFileName划分前缀
+func renamePolicy(policy CheckTimeToOpenNewFileFunc, name, suffix string) CheckTimeToOpenNewFileFunc {
+	return func(lastOpenFileTime *time.Time, isNeverOpenFile bool) (string, bool) {
+		fileName, ok := policy(lastOpenFileTime, isNeverOpenFile)
+		if !ok {
+			return fileName, ok
+		}
+		prefix, rest := splitFileName(fileName)
+		if strings.HasPrefix(fileName, name+".") {
+			prefix, rest = name, fileName[len(name):]
+		}
+		return prefix + suffix + rest, ok
+	}
+}
+
+// splitFileName 把文件名分成前缀和时间部分, 时间部分以第一个"."加数字开始
+// 如svc.2006-01-02.log -> svc, .2006-01-02.log; 没有时间部分时只分出扩展名
+func splitFileName(fileName string) (prefix, rest string) {
+	for i := 0; i+1 < len(fileName); i++ {
+		if fileName[i] == '.' && fileName[i+1] >= '0' && fileName[i+1] <= '9' {
+			return fileName[:i], fileName[i:]
+		}
+	}
+	ext := filepath.Ext(fileName)
+	return fileName[:len(fileName)-len(ext)], ext
+}
+
+// defaultInstancePolicy 兼容旧的策略变量, 每次调用时取默认logger的名字和时区, 没有默认logger时名字为空
+func defaultInstancePolicy(period RotationPeriod) CheckTimeToOpenNewFileFunc {
+	return func(lastOpenFileTime *time.Time, isNeverOpenFile bool) (string, bool) {
+		var name string
+		var loc *time.Location
+		if l := Default(); l != nil {
+			name, loc = l.name, l.location
+		}
+		return NewRotationPolicy(name, period, loc, nil)(lastOpenFileTime, isNeverOpenFile)
+	}
+}
+
+// 以下策略的文件名使用默认logger的名字, 用于New创建的logger时文件名与该logger无关
+//
+// Deprecated: logger请使用WithRotationPeriod, 独立使用FileLoggerWriter时请使用NewRotationPolicy
+var (
+	// OpenNewFileByByDateHour 按天切换文件, name.01-02.log
+	OpenNewFileByByDateHour = defaultInstancePolicy(LegacyDailyPeriod)
+	OpenNewFileHourly       = defaultInstancePolicy(HourlyPeriod)
+	OpenNewFileDaily        = defaultInstancePolicy(DailyPeriod)
+	OpenNewFileWeekly       = defaultInstancePolicy(WeeklyPeriod)
+)
+
+// OpenNewFileEveryMinutes 每n分钟切换文件, 文件名使用默认logger的名字
+//
+// Deprecated: 请使用WithRotationPeriod(EveryMinutesPeriod(n))或NewRotationPolicy
+func OpenNewFileEveryMinutes(n int) CheckTimeToOpenNewFileFunc {
+	return defaultInstancePolicy(EveryMinutesPeriod(n))
+}
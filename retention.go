@@ -0,0 +1,137 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupFile 一个历史文件, 压缩未完成时可能对应压缩前后两个文件
+type backupFile struct {
+	names   []string
+	modTime time.Time
+}
+
+func (w *FileLoggerWriter) needPrune() bool {
+	return w.maxBackups > 0 || w.maxAge > 0
+}
+
+// triggerPrune 通知后台清理历史文件, 不阻塞写日志协程
+func (w *FileLoggerWriter) triggerPrune() {
+	if !w.needPrune() {
+		return
+	}
+	select {
+	case w.pruneCh <- struct{}{}:
+	default:
+	}
+}
+
+func (w *FileLoggerWriter) pruneLoop() {
+	for {
+		select {
+		case <-w.pruneCh:
+			if err := w.prune(w.activeFileName()); err != nil {
+				w.reportError(fmt.Errorf("prune log files failed: %w", err))
+			}
+		case <-w.doneCh:
+			return
+		}
+	}
+}
+
+func (w *FileLoggerWriter) isBackupFile(fileName, current string) bool {
+	if fileName == current {
+		return false
+	}
+	// 前缀后紧跟日期, 避免把name.error等其他writer的文件当成自己的
+	rest := strings.TrimPrefix(fileName, w.backupPrefix(current)+".")
+	if rest == fileName || rest == "" || rest[0] < '0' || rest[0] > '9' {
+		return false
+	}
+	if strings.HasSuffix(fileName, ".log") {
+		return true
+	}
+	return w.needCompress() && strings.HasSuffix(fileName, ".log"+w.compression.Ext)
+}
+
+// backupPrefix 历史文件名前缀, 没有设置或与当前文件名不符时从当前文件名推断
+func (w *FileLoggerWriter) backupPrefix(current string) string {
+	if current == "" || strings.HasPrefix(current, w.filePrefix+".") {
+		return w.filePrefix
+	}
+	prefix, _ := splitFileName(current)
+	return prefix
+}
+
+// prune 按数量和时间清理历史文件, current为当前正在写的文件
+func (w *FileLoggerWriter) prune(current string) error {
+	entries, err := os.ReadDir(w.baseDir)
+	if err != nil {
+		return err
+	}
+
+	// 压缩过程中x.log和x.log.gz同时存在, 按去掉压缩后缀的名字算作一个历史文件
+	groups := make(map[string]*backupFile)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !w.isBackupFile(entry.Name(), current) {
+			continue
+		}
+		base := entry.Name()
+		if w.needCompress() {
+			base = strings.TrimSuffix(base, w.compression.Ext)
+		}
+		if _, compressing := w.compressing.Load(filepath.Join(w.baseDir, base)); compressing {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		f, ok := groups[base]
+		if !ok {
+			f = &backupFile{modTime: info.ModTime()}
+			groups[base] = f
+		}
+		f.names = append(f.names, entry.Name())
+		if info.ModTime().After(f.modTime) {
+			f.modTime = info.ModTime()
+		}
+	}
+	files := make([]backupFile, 0, len(groups))
+	for _, f := range groups {
+		files = append(files, *f)
+	}
+
+	// 新的在前
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+
+	var removes []backupFile
+	if w.maxBackups > 0 && len(files) > w.maxBackups {
+		removes = append(removes, files[w.maxBackups:]...)
+		files = files[:w.maxBackups]
+	}
+	if w.maxAge > 0 {
+		cutoff := w.clock().Add(-w.maxAge)
+		for _, f := range files {
+			if f.modTime.Before(cutoff) {
+				removes = append(removes, f)
+			}
+		}
+	}
+
+	var lastErr error
+	for _, f := range removes {
+		for _, name := range f.names {
+			if err := os.Remove(filepath.Join(w.baseDir, name)); err != nil && !os.IsNotExist(err) {
+				lastErr = err
+			}
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,218 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testClock 手动推进的时钟, 供切换策略和writer共用
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock(now time.Time) *testClock {
+	return &testClock{now: now}
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// waitFiles 等待后台清理、压缩完成后目录中正好是want
+func waitFiles(t *testing.T, dir string, want ...string) {
+	t.Helper()
+	sort.Strings(want)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := listDir(t, dir)
+		if reflect.DeepEqual(got, want) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("files = %v, want %v", got, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func touch(t *testing.T, name string, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(name, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func startWriter(t *testing.T, w *FileLoggerWriter) {
+	t.Helper()
+	go w.Loop()
+	t.Cleanup(func() { w.Close(context.Background()) })
+}
+
+// writeAndStamp 写入一行并把当前文件的修改时间设为clock的时间, 清理时按修改时间排序不受文件系统时间精度影响
+func writeAndStamp(t *testing.T, w *FileLoggerWriter, clock *testClock, line string) {
+	t.Helper()
+	w.Write(line)
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	now := clock.Now()
+	if err := os.Chtimes(filepath.Join(w.baseDir, w.activeFileName()), now, now); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSizeRotationMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local)
+	clock := newTestClock(start)
+	policy := NewRotationPolicy("app", DailyPeriod, time.Local, clock.Now)
+	w := NewFileLoggerWriter(dir, 16, 0, policy, 16, 0755,
+		WithWriterMaxBackups(2), WithWriterClock(clock.Now))
+	startWriter(t, w)
+
+	for i := 0; i < 5; i++ {
+		writeAndStamp(t, w, clock, fmt.Sprintf("line %02d 0123456789\n", i))
+		clock.Add(time.Second)
+	}
+
+	current := filepath.Join(dir, "app.2026-10-17.log")
+	newest := backUpName(current, start.Add(4*time.Second))
+	older := backUpName(current, start.Add(3*time.Second))
+	waitFiles(t, dir, filepath.Base(current), filepath.Base(newest), filepath.Base(older))
+
+	if got := readFile(t, current); got != "line 04 0123456789\n" {
+		t.Errorf("current = %q", got)
+	}
+	if got := readFile(t, newest); got != "line 03 0123456789\n" {
+		t.Errorf("newest backup = %q", got)
+	}
+	if got := readFile(t, older); got != "line 02 0123456789\n" {
+		t.Errorf("older backup = %q", got)
+	}
+}
+
+func TestDailyRotationMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	clock := newTestClock(time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local))
+	policy := NewRotationPolicy("app", DailyPeriod, time.Local, clock.Now)
+	w := NewFileLoggerWriter(dir, 1<<20, 0, policy, 16, 0755,
+		WithWriterMaxBackups(2), WithWriterClock(clock.Now))
+	startWriter(t, w)
+
+	for i := 0; i < 4; i++ {
+		writeAndStamp(t, w, clock, fmt.Sprintf("day %d\n", i))
+		clock.Add(24 * time.Hour)
+	}
+
+	waitFiles(t, dir, "app.2026-10-18.log", "app.2026-10-19.log", "app.2026-10-20.log")
+	if got := readFile(t, filepath.Join(dir, "app.2026-10-20.log")); got != "day 3\n" {
+		t.Errorf("current = %q", got)
+	}
+}
+
+func TestPruneMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local)
+	files := []struct {
+		name string
+		age  time.Duration
+	}{
+		{"app.2026-10-17.log", 0},
+		{"app.2026-10-16.log", 24 * time.Hour},
+		{"app.2026-10-10.log", 7 * 24 * time.Hour},
+		{"app.2026-10-10_2026-10-10T08-00-00.000.log", 7 * 24 * time.Hour},
+		// 不属于该writer或不是历史文件的都不能删
+		{"app.error.2026-10-01.log", 16 * 24 * time.Hour},
+		{"other.2026-10-01.log", 16 * 24 * time.Hour},
+		{"app.2026-10-01.log.gz", 16 * 24 * time.Hour},
+		{"app.log", 16 * 24 * time.Hour},
+	}
+	for _, f := range files {
+		touch(t, filepath.Join(dir, f.name), "x\n", now.Add(-f.age))
+	}
+
+	w := NewFileLoggerWriter(dir, 1<<20, 0, nil, 16, 0755,
+		WithWriterMaxAge(72*time.Hour), WithWriterClock(func() time.Time { return now }))
+	startWriter(t, w)
+	if err := w.prune("app.2026-10-17.log"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"app.2026-10-01.log.gz", "app.2026-10-16.log", "app.2026-10-17.log",
+		"app.error.2026-10-01.log", "app.log", "other.2026-10-01.log"}
+	if got := listDir(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestIsBackupFile(t *testing.T) {
+	tests := []struct {
+		prefix      string
+		compression *Compression
+		current     string
+		name        string
+		want        bool
+	}{
+		{"app", nil, "app.2026-10-17.log", "app.2026-10-16.log", true},
+		{"app", nil, "app.2026-10-17.log", "app.2026-10-17.log", false},
+		{"app", nil, "app.2026-10-17.log", "app.2026-10-17_2026-10-17T08-00-00.000.log", true},
+		{"app", nil, "app.2026-10-17.log", "app.error.2026-10-16.log", false},
+		{"app", nil, "app.2026-10-17.log", "apps.2026-10-16.log", false},
+		{"app", nil, "app.2026-10-17.log", "app.2026-10-16.log.gz", false},
+		{"app", GzipCompression, "app.2026-10-17.log", "app.2026-10-16.log.gz", true},
+		{"app", GzipCompression, "app.2026-10-17.log", "app.2026-10-16.log.gz.tmp", false},
+		// 没有设置前缀时从当前文件名推断
+		{"", nil, "svc.error.2026-10-17.log", "svc.error.2026-10-16.log", true},
+		{"", nil, "svc.error.2026-10-17.log", "svc.2026-10-16.log", false},
+		{"app", nil, "svc.2026-10-17.log", "svc.2026-10-16.log", true},
+	}
+	for _, tt := range tests {
+		w := &FileLoggerWriter{filePrefix: tt.prefix, compression: tt.compression}
+		if got := w.isBackupFile(tt.name, tt.current); got != tt.want {
+			t.Errorf("prefix %q current %q: isBackupFile(%q) = %v, want %v",
+				tt.prefix, tt.current, tt.name, got, tt.want)
+		}
+	}
+}
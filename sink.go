@@ -0,0 +1,293 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Sink 日志输出目的地, data为编码后的一条日志, WriteLog返回后logger不会再修改data
+// WriteLog在调用日志函数的协程中同步执行, 耗时的实现应自行异步处理, 如WriterSink
+type Sink interface {
+	WriteLog(level int, data []byte) error
+	Close(ctx context.Context) error
+}
+
+// SinkSpec 一个输出目的地及其最低等级和编码, Encoder为nil时使用logger的编码
+type SinkSpec struct {
+	Sink    Sink
+	Level   int
+	Encoder Encoder
+}
+
+// writeSinks 依次写入各个输出, 单个输出出错或panic不影响其他输出
+func (l *Logger) writeSinks(r *Record, record string) {
+	for _, spec := range l.sinks {
+		if r.Level < spec.Level {
+			continue
+		}
+		data := record
+		if spec.Encoder != nil {
+			data = encodeRecord(spec.Encoder, r)
+		}
+		if err := safeSinkWrite(spec.Sink, r.Level, []byte(data)); err != nil {
+			l.reportSinkError(err)
+		}
+	}
+}
+
+// sinkErrorReporting 正在报告输出错误, 错误回调中写日志又出错时直接输出到stderr, 避免无限递归
+var sinkErrorReporting int32
+
+func (l *Logger) reportSinkError(err error) {
+	if !atomic.CompareAndSwapInt32(&sinkErrorReporting, 0, 1) {
+		defaultErrorHandler(err)
+		return
+	}
+	defer atomic.StoreInt32(&sinkErrorReporting, 0)
+	l.reportError(err)
+}
+
+func safeSinkWrite(sink Sink, level int, data []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("log sink panic: %v", r)
+		}
+	}()
+	return sink.WriteLog(level, data)
+}
+
+func (l *Logger) closeSinks(ctx context.Context) error {
+	var lastErr error
+	for _, spec := range l.sinks {
+		if err := spec.Sink.Close(ctx); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// fileWriters 主文件和所有FileSink的writer, 包括按等级分文件和error文件
+func (l *Logger) fileWriters() []*FileLoggerWriter {
+	writers := []*FileLoggerWriter{l.writer}
+	for _, spec := range l.sinks {
+		sink := spec.Sink
+		if only, ok := sink.(levelOnlySink); ok {
+			sink = only.Sink
+		}
+		if fs, ok := sink.(*FileSink); ok {
+			writers = append(writers, fs.writer)
+		}
+	}
+	return writers
+}
+
+// reopener 可以重新打开文件的输出
+type reopener interface {
+	Reopen() error
+}
+
+func (l *Logger) reopenSinks() error {
+	var lastErr error
+	for _, spec := range l.sinks {
+		sink := spec.Sink
+		if only, ok := sink.(levelOnlySink); ok {
+			sink = only.Sink
+		}
+		if r, ok := sink.(reopener); ok {
+			if err := r.Reopen(); err != nil {
+				lastErr = err
+			}
+		}
+	}
+	return lastErr
+}
+
+func (l *Logger) reportError(err error) {
+	if l.errorHandler != nil {
+		l.errorHandler(err)
+		return
+	}
+	defaultErrorHandler(err)
+}
+
+// levelOnlySink 只接收指定等级的日志
+type levelOnlySink struct {
+	Sink
+	level int
+}
+
+func (s levelOnlySink) WriteLog(level int, data []byte) error {
+	if level != s.level {
+		return nil
+	}
+	return s.Sink.WriteLog(level, data)
+}
+
+// FileSink 基于FileLoggerWriter的输出, 异步写文件
+type FileSink struct {
+	writer *FileLoggerWriter
+}
+
+// NewFileSink 创建文件输出并启动writer的Loop
+func NewFileSink(writer *FileLoggerWriter) *FileSink {
+	go writer.Loop()
+	return &FileSink{writer: writer}
+}
+
+func (s *FileSink) WriteLog(level int, data []byte) error {
+	s.writer.WriteLevel(level, string(data))
+	return nil
+}
+
+func (s *FileSink) Close(ctx context.Context) error {
+	return s.writer.Close(ctx)
+}
+
+// Reopen 重新打开文件
+func (s *FileSink) Reopen() error {
+	return s.writer.Reopen()
+}
+
+// writerSinkChanLen WriterSink的缓冲队列长度
+const writerSinkChanLen = 1024
+
+// WriterSink 输出到io.Writer, 如os.Stderr或网络连接
+// 由单独的协程写入, 队列满时丢弃新日志, 对端卡住不会阻塞写日志的协程
+type WriterSink struct {
+	w       io.Writer
+	ch      chan []byte
+	closeCh chan struct{}
+	doneCh  chan struct{}
+	once    sync.Once
+	dropped atomic.Uint64
+	mu      sync.Mutex
+	err     error // 后台写入的错误, 下次WriteLog时返回
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	s := &WriterSink{
+		w:       w,
+		ch:      make(chan []byte, writerSinkChanLen),
+		closeCh: make(chan struct{}),
+		doneCh:  make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+func (s *WriterSink) loop() {
+	defer close(s.doneCh)
+	for {
+		select {
+		case data := <-s.ch:
+			s.write(data)
+		case <-s.closeCh:
+			for {
+				select {
+				case data := <-s.ch:
+					s.write(data)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *WriterSink) write(data []byte) {
+	if _, err := s.w.Write(data); err != nil {
+		s.mu.Lock()
+		s.err = err
+		s.mu.Unlock()
+	}
+}
+
+// WriteLog 放入队列, 返回上次后台写入的错误
+func (s *WriterSink) WriteLog(_ int, data []byte) error {
+	select {
+	case <-s.closeCh:
+		s.dropped.Add(1)
+		return nil
+	default:
+	}
+	select {
+	case s.ch <- data:
+	default:
+		s.dropped.Add(1)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.err
+	s.err = nil
+	return err
+}
+
+// Dropped 因队列满或已关闭被丢弃的日志条数
+func (s *WriterSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close 写完队列中的日志, 如果io.Writer实现了io.Closer则关闭它
+// ctx超时时也会关闭io.Writer, 让卡住的写入返回
+func (s *WriterSink) Close(ctx context.Context) error {
+	s.once.Do(func() { close(s.closeCh) })
+	var err error
+	select {
+	case <-s.doneCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if closer, ok := s.w.(io.Closer); ok {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// RingSink 在内存中保留最近的n条日志
+type RingSink struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+func NewRingSink(n int) *RingSink {
+	if n <= 0 {
+		n = 1
+	}
+	return &RingSink{lines: make([]string, n)}
+}
+
+func (s *RingSink) WriteLog(_ int, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines[s.next] = string(data)
+	s.next++
+	if s.next == len(s.lines) {
+		s.next = 0
+		s.full = true
+	}
+	return nil
+}
+
+func (s *RingSink) Close(_ context.Context) error {
+	return nil
+}
+
+// Lines 按时间顺序返回保留的日志
+func (s *RingSink) Lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.full {
+		return append([]string(nil), s.lines[:s.next]...)
+	}
+	lines := make([]string, 0, len(s.lines))
+	lines = append(lines, s.lines[s.next:]...)
+	return append(lines, s.lines[:s.next]...)
+}
@@ -0,0 +1,66 @@
+package logger
+
+// 结构化日志, msg原样输出, 字段由输出格式负责展开
+
+// Trace 跟踪类型日志
+func Trace(msg string, fields ...Field) {
+	Default().logw(TraceLevel, msg, fields)
+}
+
+// Debug 调试类型日志
+func Debug(msg string, fields ...Field) {
+	Default().logw(DebugLevel, msg, fields)
+}
+
+// Info 程序信息类型日志
+func Info(msg string, fields ...Field) {
+	Default().logw(InfoLevel, msg, fields)
+}
+
+// Warn 警告类型日志
+func Warn(msg string, fields ...Field) {
+	Default().logw(WarnLevel, msg, fields)
+}
+
+// Error 错误类型日志
+func Error(msg string, fields ...Field) {
+	Default().logw(ErrorLevel, msg, fields)
+}
+
+// Stack 堆栈debug日志
+func Stack(msg string, fields ...Field) {
+	Default().logw(StackLevel, msg, fields)
+}
+
+// Fatal 致命错误类型日志
+func Fatal(msg string, fields ...Field) {
+	Default().fatalw(msg, fields)
+}
+
+func (l *Logger) Trace(msg string, fields ...Field) {
+	l.logw(TraceLevel, msg, fields)
+}
+
+func (l *Logger) Debug(msg string, fields ...Field) {
+	l.logw(DebugLevel, msg, fields)
+}
+
+func (l *Logger) Info(msg string, fields ...Field) {
+	l.logw(InfoLevel, msg, fields)
+}
+
+func (l *Logger) Warn(msg string, fields ...Field) {
+	l.logw(WarnLevel, msg, fields)
+}
+
+func (l *Logger) Error(msg string, fields ...Field) {
+	l.logw(ErrorLevel, msg, fields)
+}
+
+func (l *Logger) Stack(msg string, fields ...Field) {
+	l.logw(StackLevel, msg, fields)
+}
+
+func (l *Logger) Fatal(msg string, fields ...Field) {
+	l.fatalw(msg, fields)
+}
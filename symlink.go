@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// updateSymlink 让symlinkName指向当前文件, 先建临时链接再rename, 读取方不会看到链接缺失
+func (w *FileLoggerWriter) updateSymlink() {
+	if w.symlinkName == "" || w.symlinkName == w.currentFileName {
+		return
+	}
+	link := filepath.Join(w.baseDir, w.symlinkName)
+	if target, err := os.Readlink(link); err == nil && target == w.currentFileName {
+		return
+	}
+
+	tmp := fmt.Sprintf("%s.%d.tmp", link, os.Getpid())
+	os.Remove(tmp)
+	if err := os.Symlink(w.currentFileName, tmp); err != nil {
+		w.reportError(fmt.Errorf("create log symlink failed: %w", err))
+		return
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		os.Remove(tmp)
+		w.reportError(fmt.Errorf("update log symlink failed: %w", err))
+	}
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func skipSymlinkOnWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on windows")
+	}
+}
+
+func assertLink(t *testing.T, link, target string) {
+	t.Helper()
+	got, err := os.Readlink(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != target {
+		t.Errorf("%s -> %s, want %s", filepath.Base(link), got, target)
+	}
+}
+
+func TestSymlinkRetarget(t *testing.T) {
+	skipSymlinkOnWindows(t)
+	dir := t.TempDir()
+	clock := newTestClock(time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local))
+	policy := NewRotationPolicy("app", DailyPeriod, time.Local, clock.Now)
+	w := NewFileLoggerWriter(dir, 1<<20, 0, policy, 16, 0755,
+		WithWriterSymlink("app.log"), WithWriterMaxBackups(1), WithWriterClock(clock.Now))
+	startWriter(t, w)
+	link := filepath.Join(dir, "app.log")
+
+	days := []struct {
+		line   string
+		target string
+	}{
+		{"day 0\n", "app.2026-10-17.log"},
+		{"day 1\n", "app.2026-10-18.log"},
+		{"day 2\n", "app.2026-10-19.log"},
+	}
+	for _, day := range days {
+		writeAndStamp(t, w, clock, day.line)
+		assertLink(t, link, day.target)
+		if got := readFile(t, link); got != day.line {
+			t.Errorf("read through link = %q, want %q", got, day.line)
+		}
+		clock.Add(24 * time.Hour)
+	}
+
+	// 软链接不算历史文件, 清理时不会删除
+	waitFiles(t, dir, "app.2026-10-18.log", "app.2026-10-19.log", "app.log")
+}
+
+func TestSymlinkSizeRotation(t *testing.T) {
+	skipSymlinkOnWindows(t)
+	dir := t.TempDir()
+	clock := newTestClock(time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local))
+	policy := NewRotationPolicy("app", DailyPeriod, time.Local, clock.Now)
+	w := NewFileLoggerWriter(dir, 8, 0, policy, 16, 0755,
+		WithWriterSymlink("app.log"), WithWriterClock(clock.Now))
+	startWriter(t, w)
+	link := filepath.Join(dir, "app.log")
+
+	writeFlush(t, w, "first line\n")
+	clock.Add(time.Second)
+	writeFlush(t, w, "second line\n")
+
+	assertLink(t, link, "app.2026-10-17.log")
+	if got := readFile(t, link); got != "second line\n" {
+		t.Errorf("read through link = %q", got)
+	}
+}
+
+func TestLoggerSymlink(t *testing.T) {
+	skipSymlinkOnWindows(t)
+	dir := t.TempDir()
+	clock := newTestClock(time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local))
+	l, err := New(WithPath(dir), WithAppName("svc"), WithErrorFile(ErrorLevel), WithSymlink(),
+		WithRotationPolicy(NewRotationPolicy("svc", DailyPeriod, time.Local, clock.Now)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close(context.Background())
+
+	l.Error("boom")
+	l.Flush()
+
+	assertLink(t, filepath.Join(dir, "svc.log"), "svc.2026-10-17.log")
+	assertLink(t, filepath.Join(dir, "svc.error.log"), "svc.error.2026-10-17.log")
+	if got := readFile(t, filepath.Join(dir, "svc.error.log")); !strings.Contains(got, "boom") {
+		t.Errorf("read through error link = %q", got)
+	}
+}
@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 模板中支持的占位符
+const (
+	tplTime   = "time"   // {time} 或 {time:2006-01-02T15:04:05.000Z07:00} 或 {time:epoch_millis}
+	tplLevel  = "level"  // info
+	tplLEVEL  = "LEVEL"  // INFO
+	tplApp    = "app"    // 日志名字
+	tplPrefix = "prefix" // 标识
+	tplModule = "module" // 模块名
+	tplCaller = "caller" // file:line
+	tplFile   = "file"
+	tplLine   = "line"
+	tplFunc   = "func"
+	tplMsg    = "msg"
+	tplFields = "fields" // key=value key=value
+	tplStack  = "stack"  // 不写时堆栈追加在下一行
+)
+
+type templateSegment struct {
+	literal string
+	name    string // 为空时是普通文本
+	arg     string
+}
+
+// TemplateEncoder 按模板输出, 如"{time:2006-01-02T15:04:05.000Z07:00} {level} {caller} {msg}"
+// 用{{和}}输出花括号
+type TemplateEncoder struct {
+	segments []templateSegment
+	hasStack bool
+}
+
+// NewTemplateEncoder 解析模板, 占位符未闭合或不支持时返回错误
+func NewTemplateEncoder(pattern string) (*TemplateEncoder, error) {
+	enc := &TemplateEncoder{}
+	var literal strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		if c == '}' {
+			if i+1 < len(pattern) && pattern[i+1] == '}' {
+				i++
+			}
+			literal.WriteByte('}')
+			continue
+		}
+		if c != '{' {
+			literal.WriteByte(c)
+			continue
+		}
+		if i+1 < len(pattern) && pattern[i+1] == '{' {
+			literal.WriteByte('{')
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(pattern[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("log template: unclosed placeholder at %d", i)
+		}
+		name, arg := pattern[i+1:i+end], ""
+		if idx := strings.IndexByte(name, ':'); idx >= 0 {
+			name, arg = name[:idx], name[idx+1:]
+		}
+		switch name {
+		case tplTime:
+			if arg == "" {
+				arg = TimeFormatDefault
+			}
+		case tplLevel, tplLEVEL, tplApp, tplPrefix, tplModule, tplCaller, tplFile, tplLine, tplFunc, tplMsg, tplFields:
+		case tplStack:
+			enc.hasStack = true
+		default:
+			return nil, fmt.Errorf("log template: unknown placeholder {%s}", name)
+		}
+
+		if literal.Len() > 0 {
+			enc.segments = append(enc.segments, templateSegment{literal: literal.String()})
+			literal.Reset()
+		}
+		enc.segments = append(enc.segments, templateSegment{name: name, arg: arg})
+		i += end
+	}
+	if literal.Len() > 0 {
+		enc.segments = append(enc.segments, templateSegment{literal: literal.String()})
+	}
+	return enc, nil
+}
+
+func (e *TemplateEncoder) Encode(buf *bytes.Buffer, r *Record) error {
+	for _, seg := range e.segments {
+		switch seg.name {
+		case "":
+			buf.WriteString(seg.literal)
+		case tplTime:
+			buf.WriteString(formatTime(r.Time, seg.arg))
+		case tplLevel:
+			buf.WriteString(levelNames[r.Level])
+		case tplLEVEL:
+			buf.WriteString(strings.ToUpper(levelNames[r.Level]))
+		case tplApp:
+			buf.WriteString(r.App)
+		case tplPrefix:
+			buf.WriteString(r.Prefix)
+		case tplModule:
+			buf.WriteString(r.Module)
+		case tplCaller:
+			if r.Caller != nil {
+				buf.WriteString(r.Caller.File)
+				buf.WriteString(":")
+				buf.WriteString(strconv.Itoa(r.Caller.Line))
+			}
+		case tplFile:
+			if r.Caller != nil {
+				buf.WriteString(r.Caller.File)
+			}
+		case tplLine:
+			if r.Caller != nil {
+				buf.WriteString(strconv.Itoa(r.Caller.Line))
+			}
+		case tplFunc:
+			if r.Caller != nil {
+				buf.WriteString(r.Caller.FuncName)
+			}
+		case tplMsg:
+			buf.WriteString(r.Message)
+		case tplFields:
+			buf.WriteString(strings.TrimPrefix(buildFields(r.Fields), " "))
+		case tplStack:
+			buf.WriteString(r.Stack)
+		}
+	}
+	if !e.hasStack && r.Stack != "" {
+		buf.WriteString("\n")
+		buf.WriteString(r.Stack)
+	}
+	buf.WriteString("\n")
+	return nil
+}
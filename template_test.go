@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestNewTemplateEncoder(t *testing.T) {
+	r := &Record{
+		Time:    time.Date(2026, 10, 17, 8, 30, 5, 120000000, time.UTC),
+		Level:   InfoLevel,
+		App:     "game",
+		Prefix:  "p1",
+		Module:  "battle",
+		Caller:  &CallInfoSt{File: "battle/fight.go", Line: 12, FuncName: "Attack"},
+		Message: "hit",
+		Fields:  []Field{Int("dmg", 7)},
+	}
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"{level} {msg}", "info hit\n"},
+		{"{LEVEL}|{app}|{prefix}|{module}", "INFO|game|p1|battle\n"},
+		{"{caller} {file} {line} {func}", "battle/fight.go:12 battle/fight.go 12 Attack\n"},
+		{"{msg} {fields}", "hit dmg=7\n"},
+		{"{time}", "10-17 08:30:05.1200\n"},
+		{"{time:2006-01-02T15:04:05.000Z07:00}", "2026-10-17T08:30:05.120Z\n"},
+		{"{time:epoch_millis}", "1792225805120\n"},
+		{"{{{msg}}}", "{hit}\n"},
+		{"{{literal}}", "{literal}\n"},
+		{"a } b", "a } b\n"},
+		{"plain text", "plain text\n"},
+		{"", "\n"},
+	}
+	for _, tt := range tests {
+		enc, err := NewTemplateEncoder(tt.pattern)
+		if err != nil {
+			t.Errorf("pattern %q: %v", tt.pattern, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := enc.Encode(&buf, r); err != nil {
+			t.Errorf("pattern %q: %v", tt.pattern, err)
+			continue
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("pattern %q: got %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestNewTemplateEncoderErrors(t *testing.T) {
+	for _, pattern := range []string{"{msg", "{unknown}", "{level} {", "{}"} {
+		if _, err := NewTemplateEncoder(pattern); err == nil {
+			t.Errorf("pattern %q: expected error", pattern)
+		}
+	}
+}
+
+func TestTemplateEncoderStack(t *testing.T) {
+	r := &Record{Level: StackLevel, Message: "oops", Stack: "goroutine 1"}
+
+	enc, err := NewTemplateEncoder("{msg}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	enc.Encode(&buf, r)
+	if got, want := buf.String(), "oops\ngoroutine 1\n"; got != want {
+		t.Errorf("stack appended: got %q, want %q", got, want)
+	}
+
+	enc, err = NewTemplateEncoder("{msg} [{stack}]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	enc.Encode(&buf, r)
+	if got, want := buf.String(), "oops [goroutine 1]\n"; got != want {
+		t.Errorf("stack placeholder: got %q, want %q", got, want)
+	}
+}
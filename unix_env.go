@@ -18,6 +18,11 @@ func chown(name string, info os.FileInfo) error {
 		return err
 	}
 	f.Close()
+	return chownAs(name, info)
+}
+
+// chownAs 把已存在的文件属主设置成与info一致
+func chownAs(name string, info os.FileInfo) error {
 	stat := info.Sys().(*syscall.Stat_t)
 	return osChown(name, int(stat.Uid), int(stat.Gid))
 }
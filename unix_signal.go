@@ -0,0 +1,63 @@
+//go:build !windows
+// +build !windows
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// HandleSignals 开始处理信号, 返回的函数用于停止处理
+//
+//	SIGUSR1 日志等级降低一级, 已是Trace时回到配置的等级
+//	SIGUSR2 恢复配置的等级
+//	SIGHUP  重新打开日志文件, 配合logrotate等移走文件的工具使用
+func (l *Logger) HandleSignals() (stop func()) {
+	l = l.root()
+	sigCh := make(chan os.Signal, 1)
+	doneCh := make(chan struct{})
+	signal.Notify(sigCh, syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case sig := <-sigCh:
+				l.handleSignal(sig)
+			case <-doneCh:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigCh)
+			close(doneCh)
+		})
+	}
+}
+
+func (l *Logger) handleSignal(sig os.Signal) {
+	switch sig {
+	case syscall.SIGUSR1:
+		if lv := l.GetLevel(); lv > TraceLevel {
+			l.SetLevel(lv - 1)
+		} else {
+			l.SetLevel(l.configuredLevel)
+		}
+	case syscall.SIGUSR2:
+		l.SetLevel(l.configuredLevel)
+	case syscall.SIGHUP:
+		// 错误已经由writer的错误处理函数报告
+		_ = l.Reopen()
+	}
+}
+
+// HandleSignals 默认logger开始处理信号
+func HandleSignals() (stop func()) {
+	return Default().HandleSignals()
+}
@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package logger
+
+// HandleSignals windows下没有SIGUSR1等信号, 什么也不做
+func (l *Logger) HandleSignals() (stop func()) {
+	return func() {}
+}
+
+// HandleSignals 默认logger开始处理信号
+func HandleSignals() (stop func()) {
+	return Default().HandleSignals()
+}
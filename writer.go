@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -13,23 +14,6 @@ import (
 
 type CheckTimeToOpenNewFileFunc func(lastOpenFileTime *time.Time, isNeverOpenFile bool) (string, bool)
 
-var OpenNewFileByByDateHour CheckTimeToOpenNewFileFunc = func(lastOpenFileTime *time.Time, isNeverOpenFile bool) (string, bool) {
-	if isNeverOpenFile {
-		return instance.name + time.Now().Format(".01-02.log"), true
-	}
-
-	lastOpenYear, lastOpenMonth, lastOpenDay := lastOpenFileTime.Date()
-
-	now := time.Now()
-	nowYear, nowMonth, nowDay := now.Date()
-
-	if lastOpenDay != nowDay || lastOpenMonth != nowMonth || lastOpenYear != nowYear {
-		return instance.name + time.Now().Format(".01-02.log"), true
-	}
-
-	return "", false
-}
-
 var (
 	osStat      = os.Stat
 	currentTime = time.Now
@@ -45,25 +29,84 @@ type FileLoggerWriter struct {
 	checkTimeToOpenNewFile    CheckTimeToOpenNewFileFunc
 	openCurrentFileTime       *time.Time
 	currentFileName           string
-	bufCh                     chan []byte
+	hasOpened                 bool // 是否打开过文件, 出错或重新打开时fp会暂时为nil
+	bufCh                     chan bufEntry
 	isFlushing                atomic.Bool
 	flushSignCh               chan struct{}
 	flushDoneSignCh           chan error
+	reopenSignCh              chan struct{}
+	reopenDoneSignCh          chan error
+	watchInterval             time.Duration
+	lastWatchAt               time.Time
+	symlinkName               string
 	mu                        sync.Mutex
 	perm                      os.FileMode
+	filePrefix                string
+	maxBackups                int
+	maxAge                    time.Duration
+	pruneCh                   chan struct{}
+	compression               *Compression
+	compressCh                chan string
+	compressing               sync.Map // 已放入压缩队列或正在压缩的文件, 清理时跳过
+	activeName                atomic.Value
+	clock                     func() time.Time
+	location                  *time.Location
+	closed                    bool
+	closedReported            atomic.Bool
+	closeMu                   sync.RWMutex
+	closeSignCh               chan struct{}
+	doneCh                    chan struct{} // Loop退出后关闭
+	loopErr                   error
+	errorHandler              func(err error)
+	retryBackoff              time.Duration
+	retryAt                   time.Time
+	lastErr                   error
+	lastErrAt                 time.Time
+	failures                  uint64
+	consecutiveFailures       uint64
+	overflowPolicy            OverflowPolicy
+	neverDropLevel            int
+	protectedQueued           atomic.Int64 // 队列中不能丢弃的日志条数
+	dropped                   dropCounter
+	noticeFormatter           func(level int, msg string) string
 }
 
-func NewFileLoggerWriter(baseDir string, maxFileSize int64, checkFileFullIntervalSecs int64, checkTimeToOpenNewFile CheckTimeToOpenNewFileFunc, bufChanLen uint32, perm os.FileMode) *FileLoggerWriter {
-	return &FileLoggerWriter{
+var ErrWriterClosed = errors.New("log writer closed")
+
+func NewFileLoggerWriter(baseDir string, maxFileSize int64, checkFileFullIntervalSecs int64, checkTimeToOpenNewFile CheckTimeToOpenNewFileFunc, bufChanLen uint32, perm os.FileMode, opts ...WriterOption) *FileLoggerWriter {
+	w := &FileLoggerWriter{
 		baseDir:                   strings.TrimRight(baseDir, "/"),
 		maxFileSize:               maxFileSize,
 		checkFileFullIntervalSecs: checkFileFullIntervalSecs,
 		checkTimeToOpenNewFile:    checkTimeToOpenNewFile,
-		bufCh:                     make(chan []byte, bufChanLen),
+		bufCh:                     make(chan bufEntry, bufChanLen),
 		flushSignCh:               make(chan struct{}),
 		flushDoneSignCh:           make(chan error),
+		reopenSignCh:              make(chan struct{}),
+		reopenDoneSignCh:          make(chan error),
 		perm:                      perm,
+		clock:                     currentTime,
+		location:                  time.Local,
+		closeSignCh:               make(chan struct{}),
+		doneCh:                    make(chan struct{}),
+		errorHandler:              defaultErrorHandler,
+		overflowPolicy:            OverflowDropNewest,
+		neverDropLevel:            DefaultNeverDropLevel,
+		noticeFormatter:           defaultNoticeFormatter,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	if w.needPrune() {
+		w.pruneCh = make(chan struct{}, 1)
+		go w.pruneLoop()
+	}
+	if w.needCompress() {
+		w.compressCh = make(chan string, compressChanLen)
+		go w.compressLoop()
 	}
+	return w
 }
 
 func (w *FileLoggerWriter) checkFileIsFull() (bool, error) {
@@ -90,9 +133,21 @@ func (w *FileLoggerWriter) rotate() error {
 		return err
 	}
 
+	w.triggerPrune()
 	return nil
 }
 
+func (w *FileLoggerWriter) setCurrentFileName(name string) {
+	w.currentFileName = name
+	w.activeName.Store(name)
+}
+
+// activeFileName 当前正在写的文件名, 供后台协程使用
+func (w *FileLoggerWriter) activeFileName() string {
+	name, _ := w.activeName.Load().(string)
+	return name
+}
+
 func (w *FileLoggerWriter) close() error {
 	if w.fp == nil {
 		return nil
@@ -113,35 +168,37 @@ func (w *FileLoggerWriter) openNew() error {
 	info, err := osStat(name)
 	if err == nil {
 		mode = info.Mode()
-		newName := backUpName(name)
+		newName := backUpName(name, w.clock())
 		if err := os.Rename(name, newName); err != nil {
 			return err
 		}
 		if err := chown(name, info); err != nil {
 			return err
 		}
+		w.triggerCompress(newName)
 	}
 
 	fp, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
 	if err != nil {
 		return err
 	}
-	openFileTime := time.Now()
+	openFileTime := w.clock()
 
 	w.fp = fp
 	w.openCurrentFileTime = &openFileTime
 	w.isFileFull = false
 	w.lastCheckIsFullAt = 0
-	w.currentFileName = filepath.Base(name)
+	w.setCurrentFileName(filepath.Base(name))
+	w.updateSymlink()
 	return nil
 }
 
-func backUpName(name string) string {
+func backUpName(name string, t time.Time) string {
 	dir := filepath.Dir(name)
 	fileName := filepath.Base(name)
 	ext := filepath.Ext(fileName)
 	prefix := fileName[:len(fileName)-len(ext)]
-	timestamp := currentTime().Format(backupTimeFormat)
+	timestamp := t.Format(backupTimeFormat)
 	return filepath.Join(dir, fmt.Sprintf("%s_%s%s", prefix, timestamp, ext))
 }
 
@@ -167,25 +224,148 @@ func (w *FileLoggerWriter) tryOpenNewFile() error {
 		}
 	}
 
-	if w.fp, err = os.OpenFile(w.baseDir+"/"+fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, w.perm); err != nil {
+	fp, err := os.OpenFile(w.baseDir+"/"+fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, w.perm)
+	if err != nil {
+		return err
+	}
+
+	isFirstOpen := !w.hasOpened
+	lastFileName := w.currentFileName
+	if err = w.close(); err != nil {
+		fp.Close()
 		return err
 	}
 
-	openFileTime := time.Now()
+	openFileTime := w.clock()
+	w.fp = fp
 	w.openCurrentFileTime = &openFileTime
 	w.isFileFull = false
 	w.lastCheckIsFullAt = 0
-	w.currentFileName = fileName
+	w.hasOpened = true
+	w.setCurrentFileName(fileName)
+	w.updateSymlink()
+	w.triggerPrune()
+
+	if w.needCompress() {
+		if isFirstOpen {
+			go w.compressLeftovers(fileName)
+		} else if lastFileName != fileName {
+			w.triggerCompress(w.baseDir + "/" + lastFileName)
+		}
+	}
 
 	return nil
 }
 
 func (w *FileLoggerWriter) Flush() error {
 	w.isFlushing.Store(true)
-	w.flushSignCh <- struct{}{}
+	select {
+	case w.flushSignCh <- struct{}{}:
+	case <-w.doneCh:
+		w.isFlushing.Store(false)
+		return ErrWriterClosed
+	}
 	return <-w.flushDoneSignCh
 }
 
+// Reopen 关闭并按当前文件名重新打开文件, 配合外部logrotate等移走文件的工具使用
+// 由Loop执行, 可与写日志并发调用
+func (w *FileLoggerWriter) Reopen() error {
+	select {
+	case w.reopenSignCh <- struct{}{}:
+	case <-w.doneCh:
+		return ErrWriterClosed
+	}
+	return <-w.reopenDoneSignCh
+}
+
+// reopen 先写完缓冲中的日志再重新打开, 还没打开过文件时什么也不做
+func (w *FileLoggerWriter) reopen(drain func([]byte) error) error {
+	_ = drain([]byte{})
+	if w.fp == nil {
+		return nil
+	}
+	if err := w.reopenFile(); err != nil {
+		w.onWriteError(err)
+		return err
+	}
+	return nil
+}
+
+func (w *FileLoggerWriter) reopenFile() error {
+	if err := w.close(); err != nil {
+		w.reportError(err)
+	}
+	w.openCurrentFileTime = nil
+	return w.tryOpenNewFile()
+}
+
+// checkFileMoved 按watchInterval检查打开的文件是否还是目录中的当前文件
+func (w *FileLoggerWriter) checkFileMoved() (bool, error) {
+	if w.watchInterval <= 0 || w.fp == nil {
+		return false, nil
+	}
+	now := time.Now()
+	if now.Sub(w.lastWatchAt) < w.watchInterval {
+		return false, nil
+	}
+	w.lastWatchAt = now
+
+	opened, err := w.fp.Stat()
+	if err != nil {
+		return false, err
+	}
+	current, err := osStat(w.baseDir + "/" + w.currentFileName)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !os.SameFile(opened, current), nil
+}
+
+// Close 停止接收新日志, 写完缓冲中的日志并同步、关闭文件, ctx超时则提前返回
+func (w *FileLoggerWriter) Close(ctx context.Context) error {
+	w.closeMu.Lock()
+	if w.closed {
+		w.closeMu.Unlock()
+		return ErrWriterClosed
+	}
+	w.closed = true
+	w.closeMu.Unlock()
+
+	select {
+	case w.closeSignCh <- struct{}{}:
+	case <-w.doneCh:
+		return w.loopErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-w.doneCh:
+		return w.loopErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdown 由Loop在收到关闭信号后调用
+func (w *FileLoggerWriter) shutdown(drain func([]byte) error) error {
+	err := drain([]byte{})
+	w.reportDropped()
+	if w.fp != nil {
+		if syncErr := w.fp.Sync(); err == nil {
+			err = syncErr
+		}
+	}
+	if closeErr := w.close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 func (w *FileLoggerWriter) finishFlush(err error) {
 	w.isFlushing.Store(false)
 	w.flushDoneSignCh <- err
@@ -195,78 +375,139 @@ func (w *FileLoggerWriter) isFlushingNow() bool {
 	return w.isFlushing.Load()
 }
 
+// Write 写入不区分等级的日志, 按最低等级处理
 func (w *FileLoggerWriter) Write(logContent string) {
-	select {
-	case w.bufCh <- []byte(logContent):
-	default:
-		// never blocking main thread
-		fmt.Println("log content cached buf full, lost:" + logContent)
+	w.WriteLevel(TraceLevel, logContent)
+}
+
+// WriteLevel 写入日志, 队列满时按溢出策略处理, 不低于neverDropLevel的日志不会被丢弃
+func (w *FileLoggerWriter) WriteLevel(level int, logContent string) {
+	w.closeMu.RLock()
+	if w.closed {
+		w.closeMu.RUnlock()
+		w.dropClosed(level, len(logContent))
+		return
+	}
+	pending, timeout := w.enqueue(bufEntry{level: level, data: []byte(logContent)})
+	w.closeMu.RUnlock()
+
+	// 阻塞时不持有读锁, Close可以随时按ctx超时返回; Loop退出后放弃等待
+	if pending != nil {
+		w.enqueueBlocking(*pending, timeout)
 	}
 }
 
-func (w *FileLoggerWriter) Loop() error {
-	doWriteMoreAsPossible := func(buf []byte) error {
-		for {
-			var moreBuf []byte
-			select {
-			case moreBuf = <-w.bufCh:
-				buf = append(buf, moreBuf...)
-			default:
-			}
+// dropClosed 关闭后写入的日志计入丢弃, 只报告一次错误
+func (w *FileLoggerWriter) dropClosed(level int, size int) {
+	w.dropped.add(level, size)
+	if w.closedReported.CompareAndSwap(false, true) {
+		w.reportError(fmt.Errorf("%w, later records are dropped", ErrWriterClosed))
+	}
+}
 
-			if moreBuf == nil {
-				break
-			}
-		}
+// writeFile 把buf写入当前文件, 需要时切换文件
+func (w *FileLoggerWriter) writeFile(buf []byte) error {
+	if err := w.tryOpenNewFile(); err != nil {
+		return err
+	}
 
-		if len(buf) == 0 {
-			return nil
+	if moved, err := w.checkFileMoved(); err != nil {
+		return err
+	} else if moved {
+		if err := w.reopenFile(); err != nil {
+			return err
 		}
+	}
 
-		if err := w.tryOpenNewFile(); err != nil {
+	if isFull, err := w.checkFileIsFull(); err != nil {
+		return err
+	} else if isFull {
+		if err := w.rotate(); err != nil {
 			return err
 		}
+	}
 
-		if isFull, err := w.checkFileIsFull(); err != nil {
+	bufLen := len(buf)
+	var totalWrittenBytes int
+	for {
+		n, err := w.fp.Write(buf[totalWrittenBytes:])
+		if err != nil {
 			return err
-		} else if isFull {
-			if err := w.rotate(); err != nil {
-				return err
+		}
+		totalWrittenBytes += n
+		if totalWrittenBytes >= bufLen {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (w *FileLoggerWriter) Loop() (err error) {
+	defer func() {
+		w.loopErr = err
+		close(w.doneCh)
+		// 与关闭同时放入的日志已经没有协程写了, 计入丢弃
+		for {
+			select {
+			case entry := <-w.bufCh:
+				w.dequeued(entry)
+				w.drop(entry)
+			default:
+				return
 			}
 		}
+	}()
 
-		bufLen := len(buf)
-		var totalWrittenBytes int
+	doWriteMoreAsPossible := func(buf []byte) error {
 		for {
-			n, err := w.fp.Write(buf[totalWrittenBytes:])
-			if err != nil {
-				return err
+			var moreBuf []byte
+			select {
+			case entry := <-w.bufCh:
+				w.dequeued(entry)
+				moreBuf = entry.data
+				buf = append(buf, moreBuf...)
+			default:
 			}
-			totalWrittenBytes += n
-			if totalWrittenBytes >= bufLen {
+
+			if moreBuf == nil {
 				break
 			}
 		}
 
-		return nil
+		if len(buf) == 0 {
+			return nil
+		}
+
+		return w.writeBuf(buf)
 	}
 
 	for {
 		select {
-		case buf := <-w.bufCh:
-			if err := doWriteMoreAsPossible(buf); err != nil {
-				return err
-			}
+		case entry := <-w.bufCh:
+			w.dequeued(entry)
+			// 错误已经由writeBuf处理
+			_ = doWriteMoreAsPossible(entry.data)
+			w.reportDropped()
 		case _ = <-w.flushSignCh:
 			if err := doWriteMoreAsPossible([]byte{}); err != nil {
 				w.finishFlush(err)
 				break
 			}
+			w.reportDropped()
+			if w.fp == nil {
+				w.finishFlush(nil)
+				break
+			}
 			if err := w.fp.Sync(); err != nil {
 				w.finishFlush(err)
 				break
 			}
 			w.finishFlush(nil)
+		case <-w.reopenSignCh:
+			w.reopenDoneSignCh <- w.reopen(doWriteMoreAsPossible)
+		case <-w.closeSignCh:
+			return w.shutdown(doWriteMoreAsPossible)
 		}
 	}
 }
@@ -0,0 +1,140 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newReopenTestWriter(t *testing.T, opts ...WriterOption) (*FileLoggerWriter, string) {
+	t.Helper()
+	dir := t.TempDir()
+	clock := newTestClock(time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local))
+	policy := NewRotationPolicy("app", DailyPeriod, time.Local, clock.Now)
+	opts = append(opts, WithWriterClock(clock.Now))
+	w := NewFileLoggerWriter(dir, 1<<20, 0, policy, 16, 0755, opts...)
+	startWriter(t, w)
+	return w, dir
+}
+
+func writeFlush(t *testing.T, w *FileLoggerWriter, line string) {
+	t.Helper()
+	w.Write(line)
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReopenAfterRename(t *testing.T) {
+	w, dir := newReopenTestWriter(t)
+	current := filepath.Join(dir, "app.2026-10-17.log")
+	moved := filepath.Join(dir, "moved.log")
+
+	writeFlush(t, w, "before\n")
+	if err := os.Rename(current, moved); err != nil {
+		t.Fatal(err)
+	}
+	// 没有重新打开前仍然写入被移走的文件
+	writeFlush(t, w, "still old\n")
+	if err := w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	writeFlush(t, w, "after\n")
+
+	if got := readFile(t, moved); got != "before\nstill old\n" {
+		t.Errorf("moved = %q", got)
+	}
+	if got := readFile(t, current); got != "after\n" {
+		t.Errorf("current = %q", got)
+	}
+}
+
+func TestReopenBeforeOpen(t *testing.T) {
+	w, dir := newReopenTestWriter(t)
+	if err := w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if got := listDir(t, dir); len(got) != 0 {
+		t.Errorf("files = %v, want none", got)
+	}
+}
+
+func TestReopenAfterClose(t *testing.T) {
+	w, _ := newReopenTestWriter(t)
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reopen(); err != ErrWriterClosed {
+		t.Errorf("Reopen after Close = %v, want %v", err, ErrWriterClosed)
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	tests := []struct {
+		name      string
+		move      func(current, moved string) error
+		wantFiles []string
+	}{
+		{"rename", os.Rename, []string{"app.2026-10-17.log", "moved.log"}},
+		{"remove", func(current, _ string) error { return os.Remove(current) }, []string{"app.2026-10-17.log"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, dir := newReopenTestWriter(t, WithWriterWatchFile(time.Millisecond))
+			current := filepath.Join(dir, "app.2026-10-17.log")
+
+			writeFlush(t, w, "before\n")
+			if err := tt.move(current, filepath.Join(dir, "moved.log")); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(5 * time.Millisecond)
+			writeFlush(t, w, "after\n")
+
+			if got := listDir(t, dir); !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Errorf("files = %v, want %v", got, tt.wantFiles)
+			}
+			if got := readFile(t, current); got != "after\n" {
+				t.Errorf("current = %q", got)
+			}
+		})
+	}
+}
+
+func TestLoggerReopen(t *testing.T) {
+	dir := t.TempDir()
+	clock := newTestClock(time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local))
+	l, err := New(WithPath(dir), WithAppName("svc"), WithErrorFile(ErrorLevel),
+		WithRotationPolicy(NewRotationPolicy("svc", DailyPeriod, time.Local, clock.Now)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close(context.Background())
+
+	l.Error("before")
+	l.Flush()
+	for _, name := range []string{"svc.2026-10-17.log", "svc.error.2026-10-17.log"} {
+		if err := os.Rename(filepath.Join(dir, name), filepath.Join(dir, "old."+name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 子logger调用时重新打开根logger的所有文件
+	if err := l.Named("battle").Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	l.Error("after")
+	l.Flush()
+
+	for _, name := range []string{"svc.2026-10-17.log", "svc.error.2026-10-17.log"} {
+		got := readFile(t, filepath.Join(dir, name))
+		if !strings.Contains(got, "after") || strings.Contains(got, "before") {
+			t.Errorf("%s = %q", name, got)
+		}
+		if old := readFile(t, filepath.Join(dir, "old."+name)); !strings.Contains(old, "before") {
+			t.Errorf("old.%s = %q", name, old)
+		}
+	}
+}